@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"golangwithgin/config"
 	"golangwithgin/internal/app/server"
 	"golangwithgin/pkg/logger"
+	"os/signal"
+	"syscall"
+	_ "time/tzdata" // Schedule timezones; the runtime image has no zoneinfo
 
 	_ "golangwithgin/docs" // Import generated Swagger docs
 
@@ -46,7 +50,24 @@ func main() {
 	// Add Swagger documentation route
 	srv.Router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	if err := srv.Start(); err != nil {
-		log.Fatal("Failed to start server:", err)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		if err := srv.Start(); err != nil {
+			log.Fatal("Failed to start server:", err)
+		}
+	}()
+
+	// Wait for an interrupt or termination signal
+	<-ctx.Done()
+	log.Info("Shutting down server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error("Server forced to shutdown:", err)
 	}
+	log.Info("Server exited")
 } 
\ No newline at end of file
@@ -10,11 +10,14 @@ type Config struct {
 	Database DatabaseConfig  `mapstructure:"database"`
 	Sharding ShardingConfig `mapstructure:"sharding"`
 	JWT      JWTConfig      `mapstructure:"jwt"`
+	Tasks    TasksConfig    `mapstructure:"tasks"`
+	Webhooks WebhooksConfig `mapstructure:"webhooks"`
 	Logger   LoggerConfig
 }
 
 type ServerConfig struct {
-	Port string `mapstructure:"port"`
+	Port            string        `mapstructure:"port"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdownTimeout"`
 }
 
 type DatabaseConfig struct {
@@ -35,6 +38,104 @@ type JWTConfig struct {
 	Expiration time.Duration
 }
 
+type TasksConfig struct {
+	Recovery    TaskRecoveryConfig    `mapstructure:"recovery"`
+	Retry       TaskRetryConfig       `mapstructure:"retry"`
+	Results     TaskResultsConfig     `mapstructure:"results"`
+	Events      TaskEventsConfig      `mapstructure:"events"`
+	Idempotency TaskIdempotencyConfig `mapstructure:"idempotency"`
+	Priority    TaskPriorityConfig    `mapstructure:"priority"`
+	Workers     TaskWorkersConfig     `mapstructure:"workers"`
+	// Timeout limits each attempt of tasks that do not set their own timeout
+	Timeout time.Duration `mapstructure:"timeout"`
+	// ProgressInterval is the minimum time between two progress writes of
+	// a running task
+	ProgressInterval time.Duration `mapstructure:"progressInterval"`
+	// QueueCapacity is how many tasks may wait in the queue before new
+	// submissions are refused
+	QueueCapacity int `mapstructure:"queueCapacity"`
+	// UserConcurrency is the most tasks of one user the default queue runs
+	// at once across all servers; zero means no limit
+	UserConcurrency int `mapstructure:"userConcurrency"`
+	// Queues declares named queues next to the default one, each run by its
+	// own worker pool. Viper lowercases the names.
+	Queues map[string]TaskQueueConfig `mapstructure:"queues"`
+}
+
+// TaskQueueConfig sets up the worker pool of a named queue. Concurrency is
+// the most of its tasks each server runs at once, rateLimit the most it
+// starts per second and userConcurrency the most tasks of one user it runs
+// at once across all servers; zero means no limit.
+type TaskQueueConfig struct {
+	MinWorkers      int     `mapstructure:"minWorkers"`
+	Concurrency     int     `mapstructure:"concurrency"`
+	Capacity        int     `mapstructure:"capacity"`
+	RateLimit       float64 `mapstructure:"rateLimit"`
+	UserConcurrency int     `mapstructure:"userConcurrency"`
+}
+
+// TaskWorkersConfig bounds how many tasks each server runs at once. The
+// pool is resized every scale interval: workers are added once tasks have
+// waited scaleUpWait without being claimed, and retired while idle.
+type TaskWorkersConfig struct {
+	Min           int           `mapstructure:"min"`
+	Max           int           `mapstructure:"max"`
+	ScaleInterval time.Duration `mapstructure:"scaleInterval"`
+	ScaleUpWait   time.Duration `mapstructure:"scaleUpWait"`
+}
+
+// TaskRecoveryConfig sets what happens on startup to tasks left unfinished
+// by a previous run. Each policy is one of "requeue", "fail" or "ignore".
+type TaskRecoveryConfig struct {
+	Pending    string `mapstructure:"pending"`
+	Processing string `mapstructure:"processing"`
+}
+
+// TaskRetryConfig sets the default attempt limit for new tasks and the
+// exponential backoff between attempts
+type TaskRetryConfig struct {
+	MaxAttempts    int           `mapstructure:"maxAttempts"`
+	InitialBackoff time.Duration `mapstructure:"initialBackoff"`
+	MaxBackoff     time.Duration `mapstructure:"maxBackoff"`
+	Multiplier     float64       `mapstructure:"multiplier"`
+}
+
+// TaskResultsConfig limits the size in bytes of a stored task result and
+// sets how long results are kept
+type TaskResultsConfig struct {
+	MaxSize   int           `mapstructure:"maxSize"`
+	Retention time.Duration `mapstructure:"retention"`
+}
+
+// TaskEventsConfig sets how long task events are kept for clients resuming
+// an event stream
+type TaskEventsConfig struct {
+	Retention time.Duration `mapstructure:"retention"`
+}
+
+// TaskIdempotencyConfig sets how long an Idempotency-Key of a task
+// submission is remembered
+type TaskIdempotencyConfig struct {
+	Window time.Duration `mapstructure:"window"`
+}
+
+// TaskPriorityConfig sets how long a queued task waits before its priority is
+// raised by one, so that low priority tasks are not starved
+type TaskPriorityConfig struct {
+	AgingInterval time.Duration `mapstructure:"agingInterval"`
+}
+
+// WebhooksConfig sets how webhook deliveries are retried, how long the
+// receiver has to respond and how many deliveries are sent concurrently.
+// Webhooks may only target public addresses unless AllowPrivateTargets is
+// set, for example in development.
+type WebhooksConfig struct {
+	Retry               TaskRetryConfig `mapstructure:"retry"`
+	Timeout             time.Duration   `mapstructure:"timeout"`
+	Workers             int             `mapstructure:"workers"`
+	AllowPrivateTargets bool            `mapstructure:"allowPrivateTargets"`
+}
+
 type LoggerConfig struct {
 	Level string
 	File  string
@@ -48,9 +149,35 @@ func Load() (*Config, error) {
 	
 	// Set defaults
 	viper.SetDefault("server.port", "8888")
+	viper.SetDefault("server.shutdownTimeout", 30*time.Second)
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 3306)
 	viper.SetDefault("sharding.enabled", false)
+	viper.SetDefault("tasks.recovery.pending", "requeue")
+	viper.SetDefault("tasks.recovery.processing", "requeue")
+	viper.SetDefault("tasks.retry.maxAttempts", 3)
+	viper.SetDefault("tasks.retry.initialBackoff", time.Second)
+	viper.SetDefault("tasks.retry.maxBackoff", 5*time.Minute)
+	viper.SetDefault("tasks.retry.multiplier", 2.0)
+	viper.SetDefault("tasks.results.maxSize", 1<<20)
+	viper.SetDefault("tasks.results.retention", 7*24*time.Hour)
+	viper.SetDefault("tasks.events.retention", 24*time.Hour)
+	viper.SetDefault("tasks.idempotency.window", 24*time.Hour)
+	viper.SetDefault("tasks.priority.agingInterval", 30*time.Second)
+	viper.SetDefault("tasks.timeout", 10*time.Minute)
+	viper.SetDefault("tasks.progressInterval", time.Second)
+	viper.SetDefault("tasks.workers.min", 2)
+	viper.SetDefault("tasks.workers.max", 20)
+	viper.SetDefault("tasks.workers.scaleInterval", 5*time.Second)
+	viper.SetDefault("tasks.workers.scaleUpWait", 5*time.Second)
+	viper.SetDefault("tasks.queueCapacity", 1000)
+	viper.SetDefault("webhooks.retry.maxAttempts", 5)
+	viper.SetDefault("webhooks.retry.initialBackoff", 10*time.Second)
+	viper.SetDefault("webhooks.retry.maxBackoff", time.Hour)
+	viper.SetDefault("webhooks.retry.multiplier", 2.0)
+	viper.SetDefault("webhooks.timeout", 10*time.Second)
+	viper.SetDefault("webhooks.workers", 2)
+	viper.SetDefault("webhooks.allowPrivateTargets", false)
 	
 	// Read from environment variables
 	viper.AutomaticEnv()
@@ -58,6 +185,7 @@ func Load() (*Config, error) {
 	
 	// Map environment variables
 	viper.BindEnv("server.port", "SERVER_PORT")
+	viper.BindEnv("server.shutdownTimeout", "SERVER_SHUTDOWN_TIMEOUT")
 	viper.BindEnv("database.host", "DB_HOST")
 	viper.BindEnv("database.port", "DB_PORT")
 	viper.BindEnv("database.username", "DB_USER")
@@ -65,6 +193,24 @@ func Load() (*Config, error) {
 	viper.BindEnv("database.dbname", "DB_NAME")
 	viper.BindEnv("sharding.enabled", "DB_SHARDING_ENABLED")
 	viper.BindEnv("jwt.secret", "JWT_SECRET")
+	viper.BindEnv("tasks.recovery.pending", "TASK_RECOVERY_PENDING")
+	viper.BindEnv("tasks.recovery.processing", "TASK_RECOVERY_PROCESSING")
+	viper.BindEnv("tasks.retry.maxAttempts", "TASK_RETRY_MAX_ATTEMPTS")
+	viper.BindEnv("tasks.results.maxSize", "TASK_RESULTS_MAX_SIZE")
+	viper.BindEnv("tasks.results.retention", "TASK_RESULTS_RETENTION")
+	viper.BindEnv("tasks.events.retention", "TASK_EVENTS_RETENTION")
+	viper.BindEnv("tasks.idempotency.window", "TASK_IDEMPOTENCY_WINDOW")
+	viper.BindEnv("tasks.priority.agingInterval", "TASK_PRIORITY_AGING_INTERVAL")
+	viper.BindEnv("tasks.timeout", "TASK_TIMEOUT")
+	viper.BindEnv("tasks.progressInterval", "TASK_PROGRESS_INTERVAL")
+	viper.BindEnv("tasks.workers.min", "TASK_WORKERS_MIN")
+	viper.BindEnv("tasks.workers.max", "TASK_WORKERS_MAX")
+	viper.BindEnv("tasks.queueCapacity", "TASK_QUEUE_CAPACITY")
+	viper.BindEnv("tasks.userConcurrency", "TASK_USER_CONCURRENCY")
+	viper.BindEnv("webhooks.retry.maxAttempts", "WEBHOOK_MAX_ATTEMPTS")
+	viper.BindEnv("webhooks.timeout", "WEBHOOK_TIMEOUT")
+	viper.BindEnv("webhooks.workers", "WEBHOOK_WORKERS")
+	viper.BindEnv("webhooks.allowPrivateTargets", "WEBHOOK_ALLOW_PRIVATE_TARGETS")
 	
 	// Read config file
 	if err := viper.ReadInConfig(); err != nil {
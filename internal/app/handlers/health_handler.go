@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"golangwithgin/internal/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	processor domain.TaskProcessor
+}
+
+func NewHealthHandler(processor domain.TaskProcessor) *HealthHandler {
+	return &HealthHandler{
+		processor: processor,
+	}
+}
+
+// @Summary Health check
+// @Description Report that the server is up, with the state of each task queue on it. Queues are running unless an admin paused or drained them.
+// @Tags health
+// @Produce json
+// @Success 200 {object} HealthResponse
+// @Router /health [get]
+func (h *HealthHandler) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthResponse{
+		Status: "ok",
+		Queues: h.processor.States(),
+	})
+}
+
+type HealthResponse struct {
+	Status string                       `json:"status" example:"ok"`
+	Queues map[string]domain.QueueState `json:"queues"`
+}
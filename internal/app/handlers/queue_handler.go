@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"golangwithgin/internal/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QueueHandler struct {
+	processor domain.TaskProcessor
+}
+
+func NewQueueHandler(processor domain.TaskProcessor) *QueueHandler {
+	return &QueueHandler{
+		processor: processor,
+	}
+}
+
+// @Summary List task queues
+// @Description List the configured task queues for administrators. Depth and in_flight count the entries waiting in and claimed from each queue across all servers; state, workers, busy and the limits describe the queue and its worker pool on the server answering. A draining queue may already be drained on some servers.
+// @Tags queues
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} domain.QueueStats
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /queues [get]
+func (h *QueueHandler) ListQueues(c *gin.Context) {
+	queues, err := h.processor.Queues(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, queues)
+}
+
+// @Summary Pause a task queue
+// @Description Stop every server from starting the queue's tasks, for example during database maintenance. Running tasks finish, and new tasks are accepted and held until the queue is resumed. The state is stored, so it outlasts restarts; other servers follow it within a second.
+// @Tags queues
+// @Produce json
+// @Security Bearer
+// @Param name path string true "Queue name"
+// @Success 200 {object} QueueStateResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /queues/{name}/pause [post]
+func (h *QueueHandler) PauseQueue(c *gin.Context) {
+	h.changeState(c, h.processor.Pause)
+}
+
+// @Summary Resume a task queue
+// @Description Let every server start the tasks of a paused, draining or drained queue again, including the tasks it held
+// @Tags queues
+// @Produce json
+// @Security Bearer
+// @Param name path string true "Queue name"
+// @Success 200 {object} QueueStateResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /queues/{name}/resume [post]
+func (h *QueueHandler) ResumeQueue(c *gin.Context) {
+	h.changeState(c, h.processor.Resume)
+}
+
+// @Summary Drain a task queue
+// @Description Stop every server from starting the queue's tasks and refuse new submissions to it with 503. The state is stored, so it outlasts restarts; other servers follow it within a second. The queue is draining on a server until the tasks it was running there have finished, then drained; the state returned is the one on the server answering.
+// @Tags queues
+// @Produce json
+// @Security Bearer
+// @Param name path string true "Queue name"
+// @Success 200 {object} QueueStateResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /queues/{name}/drain [post]
+func (h *QueueHandler) DrainQueue(c *gin.Context) {
+	h.changeState(c, h.processor.Drain)
+}
+
+// changeState applies one of the processor's queue controls to the queue
+// named in the path
+func (h *QueueHandler) changeState(c *gin.Context, change func(ctx context.Context, queue string) (domain.QueueState, error)) {
+	name := c.Param("name")
+	state, err := change(c.Request.Context(), name)
+	if errors.Is(err, domain.ErrQueueNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, QueueStateResponse{Name: name, State: state})
+}
+
+type QueueStateResponse struct {
+	Name  string            `json:"name" example:"default"`
+	State domain.QueueState `json:"state" enums:"running,paused,draining,drained" example:"paused"`
+}
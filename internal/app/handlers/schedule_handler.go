@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"golangwithgin/internal/app/middlewares"
+	"golangwithgin/internal/domain"
+	"net/http"
+	"strconv"
+)
+
+type ScheduleHandler struct {
+	scheduleService domain.ScheduleService
+}
+
+func NewScheduleHandler(scheduleService domain.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduleService: scheduleService,
+	}
+}
+
+// @Summary Create a schedule
+// @Description Create a schedule that submits a task from its template whenever the cron expression fires in the given timezone (UTC by default). Expressions use the standard five fields or descriptors such as @hourly and @daily.
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param schedule body ScheduleRequest true "Schedule details"
+// @Success 201 {object} domain.SwaggerSchedule
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /schedules [post]
+func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule := req.toSchedule(middlewares.GetUserID(c))
+	if respondScheduleError(c, h.scheduleService.Create(c.Request.Context(), schedule)) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// @Summary List schedules
+// @Description List the caller's schedules with their next and last run times
+// @Tags schedules
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} domain.SwaggerSchedule
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /schedules [get]
+func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
+	schedules, err := h.scheduleService.List(c.Request.Context(), middlewares.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+// @Summary Get a schedule
+// @Description Get a schedule owned by the caller
+// @Tags schedules
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} domain.SwaggerSchedule
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /schedules/{id} [get]
+func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
+	id, ok := scheduleID(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.scheduleService.Get(c.Request.Context(), middlewares.GetUserID(c), id)
+	if respondScheduleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// @Summary Update a schedule
+// @Description Replace the settings of a schedule owned by the caller. Its next run is recomputed; its paused state is kept.
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Schedule ID"
+// @Param schedule body ScheduleRequest true "Schedule details"
+// @Success 200 {object} domain.SwaggerSchedule
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /schedules/{id} [put]
+func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	id, ok := scheduleID(c)
+	if !ok {
+		return
+	}
+
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule := req.toSchedule(middlewares.GetUserID(c))
+	schedule.ID = id
+	if respondScheduleError(c, h.scheduleService.Update(c.Request.Context(), schedule)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// @Summary Delete a schedule
+// @Description Delete a schedule owned by the caller. Tasks it already submitted are not affected.
+// @Tags schedules
+// @Security Bearer
+// @Param id path int true "Schedule ID"
+// @Success 204
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /schedules/{id} [delete]
+func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
+	id, ok := scheduleID(c)
+	if !ok {
+		return
+	}
+
+	if respondScheduleError(c, h.scheduleService.Delete(c.Request.Context(), middlewares.GetUserID(c), id)) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Pause a schedule
+// @Description Stop a schedule owned by the caller from submitting tasks until it is resumed
+// @Tags schedules
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} domain.SwaggerSchedule
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /schedules/{id}/pause [post]
+func (h *ScheduleHandler) PauseSchedule(c *gin.Context) {
+	id, ok := scheduleID(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.scheduleService.Pause(c.Request.Context(), middlewares.GetUserID(c), id)
+	if respondScheduleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// @Summary Resume a schedule
+// @Description Resume a paused schedule owned by the caller from its next run; runs missed while paused are skipped
+// @Tags schedules
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} domain.SwaggerSchedule
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /schedules/{id}/resume [post]
+func (h *ScheduleHandler) ResumeSchedule(c *gin.Context) {
+	id, ok := scheduleID(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.scheduleService.Resume(c.Request.Context(), middlewares.GetUserID(c), id)
+	if respondScheduleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// scheduleID parses the schedule ID path parameter, replying with 400 if it
+// is invalid
+func scheduleID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondScheduleError replies with the status matching a schedule service
+// error and reports whether it did
+func respondScheduleError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, domain.ErrScheduleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "schedule not found"})
+	case errors.Is(err, domain.ErrInvalidSchedule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+	return true
+}
+
+// Request/Response types
+type ScheduleRequest struct {
+	Name           string              `json:"name" example:"Nightly report"`
+	CronExpression string              `json:"cron_expression" binding:"required" example:"0 2 * * *"`
+	Timezone       string              `json:"timezone" example:"Europe/Berlin"`
+	Task           domain.TaskTemplate `json:"task"`
+	Paused         bool                `json:"paused"`
+}
+
+func (r ScheduleRequest) toSchedule(userID uint) *domain.Schedule {
+	return &domain.Schedule{
+		UserID:         userID,
+		Name:           r.Name,
+		CronExpression: r.CronExpression,
+		Timezone:       r.Timezone,
+		Task:           r.Task,
+		Paused:         r.Paused,
+	}
+}
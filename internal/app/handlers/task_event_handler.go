@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"golangwithgin/internal/app/middlewares"
+	"golangwithgin/internal/domain"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	eventBatchSize         = 100
+	eventPollInterval      = 2 * time.Second
+	eventHeartbeatInterval = 15 * time.Second
+)
+
+type TaskEventHandler struct {
+	taskService domain.TaskService
+	events      domain.TaskEventBroker
+}
+
+func NewTaskEventHandler(taskService domain.TaskService, events domain.TaskEventBroker) *TaskEventHandler {
+	return &TaskEventHandler{
+		taskService: taskService,
+		events:      events,
+	}
+}
+
+// @Summary Stream task events
+// @Description Stream status changes and progress of all tasks owned by the caller as Server-Sent Events. New connections receive the events recorded after they open, like WebSocket sessions; reconnecting clients resume after the ID sent in Last-Event-ID.
+// @Tags tasks
+// @Produce text/event-stream
+// @Security Bearer
+// @Param Last-Event-ID header int false "Resume after this event ID"
+// @Success 200 {object} domain.SwaggerTaskEvent
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /tasks/events [get]
+func (h *TaskEventHandler) StreamTasks(c *gin.Context) {
+	h.stream(c, 0, false)
+}
+
+// @Summary Stream events of a task
+// @Description Stream status changes and progress of a task owned by the caller as Server-Sent Events. The stream ends once the task reaches a final status. Reconnecting clients resume after the ID sent in Last-Event-ID.
+// @Tags tasks
+// @Produce text/event-stream
+// @Security Bearer
+// @Param id path int true "Task ID"
+// @Param Last-Event-ID header int false "Resume after this event ID"
+// @Success 200 {object} domain.SwaggerTaskEvent
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /tasks/{id}/events [get]
+func (h *TaskEventHandler) StreamTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	task, err := h.taskService.GetTaskStatus(c.Request.Context(), middlewares.GetUserID(c), uint(id))
+	if errors.Is(err, domain.ErrTaskNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.stream(c, task.ID, task.IsFinished())
+}
+
+// stream writes the caller's events, optionally for a single task, until
+// the client goes away or the server shuts down. A single task's stream
+// replays the task's events and ends after its final status, or right away
+// if the task had already finished and there is nothing left to replay.
+func (h *TaskEventHandler) stream(c *gin.Context, taskID uint, finished bool) {
+	lastID, resumed, err := lastEventID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid Last-Event-ID"})
+		return
+	}
+
+	userID := middlewares.GetUserID(c)
+	// New connections to the stream of all tasks start from now, as
+	// WebSocket sessions do, instead of replaying the retained history
+	if taskID == 0 && !resumed {
+		lastID, err = h.events.LastID(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	notify, unsubscribe := h.events.Subscribe(userID)
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	// Polling picks up events published by other server instances
+	poll := time.NewTicker(eventPollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(eventHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		events, err := h.events.List(c.Request.Context(), domain.TaskEventFilter{
+			UserID:  userID,
+			TaskID:  taskID,
+			AfterID: lastID,
+			Limit:   eventBatchSize,
+		})
+		if err != nil {
+			// Let the client reconnect and resume
+			return
+		}
+
+		for _, event := range events {
+			if err := writeEvent(c, event); err != nil {
+				return
+			}
+			lastID = event.ID
+			if taskID != 0 && event.Status.IsFinal() {
+				c.Writer.Flush()
+				return
+			}
+		}
+		if len(events) > 0 {
+			c.Writer.Flush()
+		}
+		if len(events) == eventBatchSize {
+			continue
+		}
+		if finished {
+			return
+		}
+
+		select {
+		case <-notify:
+		case <-poll.C:
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		case <-h.events.Done():
+			return
+		}
+	}
+}
+
+// lastEventID reads the ID to resume after from the Last-Event-ID header
+// that browsers send on reconnect, or from the last_event_id query
+// parameter, and reports whether the client sent one
+func lastEventID(c *gin.Context) (uint, bool, error) {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	if value == "" {
+		return 0, false, nil
+	}
+
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, false, err
+	}
+	return uint(id), true, nil
+}
+
+func writeEvent(c *gin.Context, event *domain.TaskEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
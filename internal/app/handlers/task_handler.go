@@ -1,51 +1,145 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"golangwithgin/internal/app/middlewares"
 	"golangwithgin/internal/domain"
 	"net/http"
 	"strconv"
+	"time"
 )
 
+// IdempotencyKeyHeader lets clients retry POST /tasks without creating the
+// task twice
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set on responses replayed for a repeated
+// idempotency key
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// queueFullRetryAfter is how many seconds clients are asked to wait before
+// submitting again while the task queue is full
+const queueFullRetryAfter = "5"
+
+// rejectQueueFull answers a submission refused because the queue is full
+func rejectQueueFull(c *gin.Context, err error) {
+	c.Header("Retry-After", queueFullRetryAfter)
+	c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+}
+
 type TaskHandler struct {
 	taskService domain.TaskService
+	idempotency domain.IdempotencyService
 }
 
-func NewTaskHandler(taskService domain.TaskService) *TaskHandler {
+func NewTaskHandler(taskService domain.TaskService, idempotency domain.IdempotencyService) *TaskHandler {
 	return &TaskHandler{
 		taskService: taskService,
+		idempotency: idempotency,
 	}
 }
 
 // @Summary Create a new task
-// @Description Submit a new task for processing
+// @Description Submit a new task for processing. The type selects the handler that runs it and defaults to "default"; unknown types are rejected. The queue selects the worker pool that runs it, with its own concurrency and rate limit, and defaults to "default"; unknown queues are rejected. Tasks with a higher priority (0-9) run first, and tasks with a run_at do not start before that time. An attempt running longer than timeout seconds, or the server default, is stopped and the task ends as timed_out. Retrying with the same Idempotency-Key and body replays the original response instead of creating another task. While the task's queue is full, submissions are refused with 503 and a Retry-After header; while it is draining, with 503 alone.
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Security Bearer
+// @Param Idempotency-Key header string false "Unique key of this submission, at most 255 characters"
 // @Param task body domain.SwaggerTask true "Task details"
 // @Success 202 {object} domain.SwaggerTask
 // @Failure 400 {object} domain.ErrorResponse
 // @Failure 401 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse "A request with the same key is in progress"
+// @Failure 422 {object} domain.ErrorResponse "The key was used with a different body"
 // @Failure 500 {object} domain.ErrorResponse
+// @Failure 503 {object} domain.ErrorResponse "The task queue is full or draining"
+// @Header 503 {integer} Retry-After "Seconds to wait before submitting again"
 // @Router /tasks [post]
 func (h *TaskHandler) CreateTask(c *gin.Context) {
 	var task domain.Task
-	if err := c.ShouldBindJSON(&task); err != nil {
+	// Keep the raw body around to fingerprint the request
+	if err := c.ShouldBindBodyWith(&task, binding.JSON); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := h.taskService.SubmitTask(&task); err != nil {
+	// Tasks are always owned by the authenticated caller
+	task.UserID = middlewares.GetUserID(c)
+
+	var record *domain.IdempotencyKey
+	if key := c.GetHeader(IdempotencyKeyHeader); key != "" {
+		var err error
+		record, err = h.idempotency.Begin(c.Request.Context(), task.UserID, key, c.MustGet(gin.BodyBytesKey).([]byte))
+		switch {
+		case errors.Is(err, domain.ErrInvalidIdempotencyKey):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, domain.ErrIdempotencyKeyInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, domain.ErrIdempotencyKeyReused):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if record.Completed() {
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(record.ResponseCode, "application/json; charset=utf-8", record.ResponseBody)
+			return
+		}
+	}
+
+	err := h.taskService.SubmitTask(c.Request.Context(), &task)
+	// The key is released or completed even if the client has gone away, so
+	// that its retry is not turned away as in progress
+	keyCtx := context.WithoutCancel(c.Request.Context())
+	if err != nil && record != nil {
+		// Nothing was created, so the client may retry with the same key
+		h.idempotency.Release(keyCtx, record)
+	}
+	if errors.Is(err, domain.ErrInvalidTask) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, domain.ErrQueueFull) {
+		rejectQueueFull(c, err)
+		return
+	}
+	if errors.Is(err, domain.ErrQueueDraining) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusAccepted, task)
+	if record == nil {
+		c.JSON(http.StatusAccepted, task)
+		return
+	}
+
+	body, err := json.Marshal(task)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// The task exists either way; a failure to store the response only means
+	// a retry with this key is rejected as in progress until the lock expires
+	h.idempotency.Complete(keyCtx, record, http.StatusAccepted, body)
+	c.Data(http.StatusAccepted, "application/json; charset=utf-8", body)
 }
 
 // @Summary Get task by ID
-// @Description Get a task's details and status by its ID
+// @Description Get a task's details and status by its ID, including the progress reported by its handler while it runs. Only tasks owned by the caller are visible.
 // @Tags tasks
 // @Accept json
 // @Produce json
@@ -55,6 +149,7 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 // @Failure 400 {object} domain.ErrorResponse
 // @Failure 401 {object} domain.ErrorResponse
 // @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
 // @Router /tasks/{id} [get]
 func (h *TaskHandler) GetTask(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -63,31 +158,243 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 		return
 	}
 
-	task, err := h.taskService.GetTaskStatus(uint(id))
+	task, err := h.taskService.GetTaskStatus(c.Request.Context(), middlewares.GetUserID(c), uint(id))
+	if errors.Is(err, domain.ErrTaskNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// @Summary Get task result
+// @Description Get the result of a completed task owned by the caller. Results are kept for a limited time after the task completes.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Task ID"
+// @Success 200 {object} domain.SwaggerTaskResult
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /tasks/{id}/result [get]
+func (h *TaskHandler) GetTaskResult(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	result, err := h.taskService.GetTaskResult(c.Request.Context(), middlewares.GetUserID(c), uint(id))
+	if errors.Is(err, domain.ErrTaskNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	if errors.Is(err, domain.ErrResultNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task result not found or expired"})
+		return
+	}
+	if errors.Is(err, domain.ErrTaskNotCompleted) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Cancel a task
+// @Description Cancel a pending or running task owned by the caller
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Task ID"
+// @Success 200 {object} domain.SwaggerTask
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /tasks/{id}/cancel [post]
+func (h *TaskHandler) CancelTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	task, err := h.taskService.CancelTask(c.Request.Context(), middlewares.GetUserID(c), uint(id))
+	if errors.Is(err, domain.ErrTaskNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
 		return
 	}
+	if errors.Is(err, domain.ErrTaskFinished) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
 	c.JSON(http.StatusOK, task)
 }
 
+// @Summary Requeue a dead task
+// @Description Give a dead task owned by the caller a fresh set of attempts and queue it again. While the task's queue is full, the requeue is refused with 503 and a Retry-After header; while it is draining, with 503 alone.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Task ID"
+// @Success 202 {object} domain.SwaggerTask
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure 503 {object} domain.ErrorResponse "The task queue is full or draining"
+// @Header 503 {integer} Retry-After "Seconds to wait before requeuing again"
+// @Router /tasks/{id}/requeue [post]
+func (h *TaskHandler) RequeueTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	task, err := h.taskService.RequeueTask(c.Request.Context(), middlewares.GetUserID(c), uint(id))
+	if errors.Is(err, domain.ErrTaskNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	if errors.Is(err, domain.ErrTaskNotDead) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, domain.ErrQueueFull) {
+		rejectQueueFull(c, err)
+		return
+	}
+	if errors.Is(err, domain.ErrQueueDraining) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, task)
+}
+
+// @Summary Get dead tasks
+// @Description Get a page of the caller's tasks that used up all their attempts. Accepts the same filters as GET /tasks except status.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param title query string false "Filter by title substring"
+// @Param created_after query string false "Only tasks created at or after this RFC 3339 time"
+// @Param created_before query string false "Only tasks created before this RFC 3339 time"
+// @Param updated_after query string false "Only tasks updated at or after this RFC 3339 time"
+// @Param updated_before query string false "Only tasks updated before this RFC 3339 time"
+// @Param sort_by query string false "Sort field" Enums(created_at, updated_at) default(created_at)
+// @Param order query string false "Sort order" Enums(asc, desc) default(desc)
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Page size" minimum(1) maximum(100) default(20)
+// @Success 200 {object} domain.SwaggerTaskPage
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /tasks/dead [get]
+func (h *TaskHandler) GetDeadTasks(c *gin.Context) {
+	h.listTasks(c, domain.TaskStatusDead)
+}
+
 // @Summary Get all tasks
-// @Description Get a list of all tasks
+// @Description Get a page of tasks owned by the caller, optionally filtered and sorted. Pass next_cursor from a response as cursor to fetch the following page. Tasks waiting to run include their queue_position, their position among the caller's own ready tasks in their queue.
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Security Bearer
-// @Success 200 {array} domain.SwaggerTask
+// @Param status query string false "Filter by status" Enums(blocked, pending, processing, completed, failed, timed_out, cancelled, dead)
+// @Param title query string false "Filter by title substring"
+// @Param created_after query string false "Only tasks created at or after this RFC 3339 time"
+// @Param created_before query string false "Only tasks created before this RFC 3339 time"
+// @Param updated_after query string false "Only tasks updated at or after this RFC 3339 time"
+// @Param updated_before query string false "Only tasks updated before this RFC 3339 time"
+// @Param sort_by query string false "Sort field" Enums(created_at, updated_at) default(created_at)
+// @Param order query string false "Sort order" Enums(asc, desc) default(desc)
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Page size" minimum(1) maximum(100) default(20)
+// @Success 200 {object} domain.SwaggerTaskPage
+// @Failure 400 {object} domain.ErrorResponse
 // @Failure 401 {object} domain.ErrorResponse
 // @Failure 500 {object} domain.ErrorResponse
 // @Router /tasks [get]
 func (h *TaskHandler) GetAllTasks(c *gin.Context) {
-	tasks, err := h.taskService.GetAllTasks()
+	h.listTasks(c, "")
+}
+
+// listTasks serves a page of the caller's tasks. A non-empty status takes
+// precedence over the status query parameter.
+func (h *TaskHandler) listTasks(c *gin.Context, status domain.TaskStatus) {
+	var req ListTasksRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if status == "" {
+		status = domain.TaskStatus(req.Status)
+	}
+
+	page, err := h.taskService.GetAllTasks(c.Request.Context(), domain.TaskFilter{
+		UserID:        middlewares.GetUserID(c),
+		Status:        status,
+		Title:         req.Title,
+		CreatedAfter:  req.CreatedAfter,
+		CreatedBefore: req.CreatedBefore,
+		UpdatedAfter:  req.UpdatedAfter,
+		UpdatedBefore: req.UpdatedBefore,
+		SortBy:        req.SortBy,
+		SortOrder:     req.Order,
+		Cursor:        req.Cursor,
+		Limit:         req.Limit,
+	})
+	if errors.Is(err, domain.ErrInvalidTaskFilter) || errors.Is(err, domain.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, tasks)
-} 
\ No newline at end of file
+	c.JSON(http.StatusOK, page)
+}
+
+// Request/Response types
+type ListTasksRequest struct {
+	Status        string     `form:"status"`
+	Title         string     `form:"title"`
+	CreatedAfter  *time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore *time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	UpdatedAfter  *time.Time `form:"updated_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	UpdatedBefore *time.Time `form:"updated_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	SortBy        string     `form:"sort_by"`
+	Order         string     `form:"order"`
+	Cursor        string     `form:"cursor"`
+	Limit         int        `form:"limit" binding:"omitempty,min=1"`
+}
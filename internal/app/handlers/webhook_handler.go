@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"golangwithgin/internal/app/middlewares"
+	"golangwithgin/internal/domain"
+	"net/http"
+	"strconv"
+)
+
+type WebhookHandler struct {
+	webhookService domain.WebhookService
+}
+
+func NewWebhookHandler(webhookService domain.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// @Summary Register a webhook
+// @Description Register an endpoint that receives the caller's task events. Deliveries are signed with an X-Webhook-Signature header holding sha256= followed by the hex HMAC-SHA256 of the body keyed by the secret. The secret is generated if omitted and only returned here. The URL must resolve to a public address.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param webhook body WebhookRequest true "Webhook details"
+// @Success 201 {object} domain.SwaggerWebhookWithSecret
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook := req.toWebhook(middlewares.GetUserID(c))
+	err := h.webhookService.Create(c.Request.Context(), webhook)
+	if errors.Is(err, domain.ErrInvalidWebhook) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateWebhookResponse{Webhook: webhook, Secret: webhook.Secret})
+}
+
+// @Summary List webhooks
+// @Description List the caller's webhooks
+// @Tags webhooks
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} domain.SwaggerWebhook
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	webhooks, err := h.webhookService.List(c.Request.Context(), middlewares.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// @Summary Get a webhook
+// @Description Get a webhook owned by the caller
+// @Tags webhooks
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} domain.SwaggerWebhook
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.webhookService.Get(c.Request.Context(), middlewares.GetUserID(c), id)
+	if respondWebhookError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// @Summary Update a webhook
+// @Description Replace the settings of a webhook owned by the caller. The secret is kept unless a new one is given.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Webhook ID"
+// @Param webhook body WebhookRequest true "Webhook details"
+// @Success 200 {object} domain.SwaggerWebhook
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook := req.toWebhook(middlewares.GetUserID(c))
+	webhook.ID = id
+	if respondWebhookError(c, h.webhookService.Update(c.Request.Context(), webhook)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// @Summary Delete a webhook
+// @Description Delete a webhook owned by the caller. Pending deliveries to it are abandoned.
+// @Tags webhooks
+// @Security Bearer
+// @Param id path int true "Webhook ID"
+// @Success 204
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	if respondWebhookError(c, h.webhookService.Delete(c.Request.Context(), middlewares.GetUserID(c), id)) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary List webhook deliveries
+// @Description List the most recent deliveries to a webhook owned by the caller, newest first
+// @Tags webhooks
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Webhook ID"
+// @Param limit query int false "Number of deliveries" minimum(1) maximum(200) default(50)
+// @Success 200 {array} domain.SwaggerWebhookDelivery
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	var req ListDeliveriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(c.Request.Context(), middlewares.GetUserID(c), id, req.Limit)
+	if respondWebhookError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// webhookID parses the webhook ID path parameter, replying with 400 if it
+// is invalid
+func webhookID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondWebhookError replies with the status matching a webhook service
+// error and reports whether it did
+func respondWebhookError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, domain.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+	case errors.Is(err, domain.ErrInvalidWebhook):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+	return true
+}
+
+// Request/Response types
+type WebhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events" example:"task.completed,task.failed"`
+	Active *bool    `json:"active"`
+}
+
+func (r WebhookRequest) toWebhook(userID uint) *domain.Webhook {
+	active := true
+	if r.Active != nil {
+		active = *r.Active
+	}
+	return &domain.Webhook{
+		UserID: userID,
+		URL:    r.URL,
+		Secret: r.Secret,
+		Events: r.Events,
+		Active: active,
+	}
+}
+
+type CreateWebhookResponse struct {
+	*domain.Webhook
+	Secret string `json:"secret"`
+}
+
+type ListDeliveriesRequest struct {
+	Limit int `form:"limit" binding:"omitempty,min=1"`
+}
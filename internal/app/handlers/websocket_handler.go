@@ -0,0 +1,424 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"golangwithgin/internal/app/middlewares"
+	"golangwithgin/internal/domain"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	wsHeartbeatInterval = 30 * time.Second
+	wsPongWait          = 2 * wsHeartbeatInterval
+	wsWriteWait         = 10 * time.Second
+	wsMaxMessageSize    = 64 << 10
+	wsSendBuffer        = 64
+	wsMaxSubscriptions  = 1000
+)
+
+type WebSocketHandler struct {
+	taskService domain.TaskService
+	events      domain.TaskEventBroker
+	upgrader    websocket.Upgrader
+}
+
+func NewWebSocketHandler(taskService domain.TaskService, events domain.TaskEventBroker) *WebSocketHandler {
+	return &WebSocketHandler{
+		taskService: taskService,
+		events:      events,
+		upgrader: websocket.Upgrader{
+			// Clients authenticate with a bearer token rather than cookies,
+			// so cross-origin connections are as safe as the REST API's
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// @Summary Open a WebSocket connection
+// @Description Upgrade to a WebSocket speaking a versioned JSON protocol. Every message is an envelope {"v":1,"type":...,"id":...,"data":...}; replies echo the request id. Clients send submit (data: a task as for POST /tasks), subscribe and unsubscribe (data: {"task_ids":[...]}) and ping. The server sends hello, submitted, subscribed, unsubscribed, pong, heartbeat, error and task events such as status and progress for subscribed tasks. Browsers may pass the JWT in the access_token query parameter.
+// @Tags websocket
+// @Security Bearer
+// @Param access_token query string false "JWT, for clients that cannot set the Authorization header"
+// @Success 101
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /ws [get]
+func (h *WebSocketHandler) Serve(c *gin.Context) {
+	userID := middlewares.GetUserID(c)
+
+	// Only events recorded after the connection opens are delivered
+	cursor, err := h.events.LastID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already replied with an error
+		return
+	}
+
+	session := &wsSession{
+		handler:       h,
+		conn:          conn,
+		ctx:           c.Request.Context(),
+		userID:        userID,
+		start:         cursor,
+		cursor:        cursor,
+		send:          make(chan WSMessage, wsSendBuffer),
+		follow:        make(chan uint, wsSendBuffer),
+		done:          make(chan struct{}),
+		subscriptions: make(map[uint]struct{}),
+	}
+	session.run()
+}
+
+// wsSession serves one WebSocket connection. A single goroutine writes to
+// the connection, as gorilla/websocket requires.
+type wsSession struct {
+	handler *WebSocketHandler
+	conn    *websocket.Conn
+	ctx     context.Context // ends when the read loop returns or the server shuts down
+	userID  uint
+	start   uint // last event ID when the connection opened
+	cursor  uint // last event ID seen by eventPump
+
+	send chan WSMessage
+	// follow hands newly submitted tasks to eventPump, which subscribes to
+	// them and delivers the events it already skipped
+	follow    chan uint
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu            sync.Mutex
+	subscriptions map[uint]struct{}
+}
+
+func (s *wsSession) run() {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		s.writePump()
+	}()
+	go func() {
+		defer wg.Done()
+		s.eventPump()
+	}()
+
+	s.reply(WSMessageHello, "", WSHello{
+		UserID:            s.userID,
+		HeartbeatInterval: int(wsHeartbeatInterval / time.Second),
+	})
+	s.readPump()
+	s.close()
+	wg.Wait()
+}
+
+func (s *wsSession) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+// readPump handles client messages until the connection fails or closes
+func (s *wsSession) readPump() {
+	s.conn.SetReadLimit(wsMaxMessageSize)
+	s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var msg WSMessage
+		if err := s.conn.ReadJSON(&msg); err != nil {
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+				s.replyError("", WSErrorBadRequest, "message is not valid JSON")
+				continue
+			}
+			return
+		}
+		s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		s.dispatch(msg)
+	}
+}
+
+func (s *wsSession) dispatch(msg WSMessage) {
+	if msg.Version != WebSocketProtocolVersion {
+		s.replyError(msg.ID, WSErrorBadRequest, "unsupported protocol version")
+		return
+	}
+
+	switch msg.Type {
+	case WSMessageSubmit:
+		s.submit(msg)
+	case WSMessageSubscribe:
+		s.subscribe(msg)
+	case WSMessageUnsubscribe:
+		s.unsubscribe(msg)
+	case WSMessagePing:
+		s.reply(WSMessagePong, msg.ID, nil)
+	default:
+		s.replyError(msg.ID, WSErrorBadRequest, "unknown message type "+msg.Type)
+	}
+}
+
+// submit creates a task like POST /tasks and subscribes to it
+func (s *wsSession) submit(msg WSMessage) {
+	var task domain.Task
+	if err := json.Unmarshal(msg.Data, &task); err != nil {
+		s.replyError(msg.ID, WSErrorBadRequest, err.Error())
+		return
+	}
+	task.UserID = s.userID
+
+	err := s.handler.taskService.SubmitTask(s.ctx, &task)
+	if errors.Is(err, domain.ErrInvalidTask) {
+		s.replyError(msg.ID, WSErrorInvalidTask, err.Error())
+		return
+	}
+	if errors.Is(err, domain.ErrQueueFull) {
+		s.replyError(msg.ID, WSErrorQueueFull, err.Error())
+		return
+	}
+	if errors.Is(err, domain.ErrQueueDraining) {
+		s.replyError(msg.ID, WSErrorQueueDraining, err.Error())
+		return
+	}
+	if err != nil {
+		s.replyError(msg.ID, WSErrorInternal, err.Error())
+		return
+	}
+
+	s.reply(WSMessageSubmitted, msg.ID, task)
+
+	select {
+	case s.follow <- task.ID:
+	case <-s.done:
+	}
+}
+
+// subscribe starts delivering events of the given tasks and reports their
+// current state
+func (s *wsSession) subscribe(msg WSMessage) {
+	var req WSSubscribeRequest
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		s.replyError(msg.ID, WSErrorBadRequest, err.Error())
+		return
+	}
+
+	// Subscribe before reading the current state so no change in between
+	// is missed
+	if !s.addSubscriptions(req.TaskIDs...) {
+		s.replyError(msg.ID, WSErrorBadRequest, "too many subscriptions")
+		return
+	}
+
+	resp := WSSubscribed{Tasks: []*domain.Task{}}
+	for _, id := range req.TaskIDs {
+		task, err := s.handler.taskService.GetTaskStatus(s.ctx, s.userID, id)
+		if errors.Is(err, domain.ErrTaskNotFound) {
+			s.removeSubscriptions(id)
+			resp.NotFound = append(resp.NotFound, id)
+			continue
+		}
+		if err != nil {
+			s.replyError(msg.ID, WSErrorInternal, err.Error())
+			return
+		}
+
+		resp.Tasks = append(resp.Tasks, task)
+	}
+
+	s.reply(WSMessageSubscribed, msg.ID, resp)
+}
+
+func (s *wsSession) unsubscribe(msg WSMessage) {
+	var req WSSubscribeRequest
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		s.replyError(msg.ID, WSErrorBadRequest, err.Error())
+		return
+	}
+
+	s.removeSubscriptions(req.TaskIDs...)
+	s.reply(WSMessageUnsubscribed, msg.ID, req)
+}
+
+// addSubscriptions subscribes to the given tasks unless that would exceed
+// the per-connection limit
+func (s *wsSession) addSubscriptions(taskIDs ...uint) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	added := 0
+	for _, id := range taskIDs {
+		if _, ok := s.subscriptions[id]; !ok {
+			added++
+		}
+	}
+	if len(s.subscriptions)+added > wsMaxSubscriptions {
+		return false
+	}
+	for _, id := range taskIDs {
+		s.subscriptions[id] = struct{}{}
+	}
+	return true
+}
+
+func (s *wsSession) removeSubscriptions(taskIDs ...uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range taskIDs {
+		delete(s.subscriptions, id)
+	}
+}
+
+func (s *wsSession) subscribed(taskID uint) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.subscriptions[taskID]
+	return ok
+}
+
+// eventPump forwards events of subscribed tasks until the session ends
+func (s *wsSession) eventPump() {
+	notify, unsubscribe := s.handler.events.Subscribe(s.userID)
+	defer unsubscribe()
+
+	// Polling picks up events published by other server instances
+	poll := time.NewTicker(eventPollInterval)
+	defer poll.Stop()
+
+	for {
+		events, err := s.handler.events.List(s.ctx, domain.TaskEventFilter{
+			UserID:  s.userID,
+			AfterID: s.cursor,
+			Limit:   eventBatchSize,
+		})
+		if err != nil {
+			s.replyError("", WSErrorInternal, "event stream interrupted")
+			s.close()
+			return
+		}
+
+		for _, event := range events {
+			s.cursor = event.ID
+			if s.subscribed(event.TaskID) {
+				s.reply(event.Type, "", event)
+			}
+		}
+		if len(events) == eventBatchSize {
+			continue
+		}
+
+		select {
+		case taskID := <-s.follow:
+			if !s.backfill(taskID) {
+				s.close()
+				return
+			}
+		case <-notify:
+		case <-poll.C:
+		case <-s.done:
+			return
+		case <-s.handler.events.Done():
+			s.close()
+			return
+		}
+	}
+}
+
+// backfill subscribes to a newly submitted task and delivers its events
+// that eventPump skipped before the subscription existed
+func (s *wsSession) backfill(taskID uint) bool {
+	if !s.addSubscriptions(taskID) {
+		s.replyError("", WSErrorBadRequest, "too many subscriptions")
+		return true
+	}
+
+	events, err := s.handler.events.List(s.ctx, domain.TaskEventFilter{
+		UserID:  s.userID,
+		TaskID:  taskID,
+		AfterID: s.start,
+	})
+	if err != nil {
+		s.replyError("", WSErrorInternal, "event stream interrupted")
+		return false
+	}
+
+	for _, event := range events {
+		if event.ID > s.cursor {
+			// Delivered by the regular stream
+			break
+		}
+		s.reply(event.Type, "", event)
+	}
+	return true
+}
+
+// writePump writes queued messages and heartbeats until the session ends,
+// then closes the connection
+func (s *wsSession) writePump() {
+	heartbeat := time.NewTicker(wsHeartbeatInterval)
+	defer heartbeat.Stop()
+	defer s.conn.Close()
+
+	for {
+		select {
+		case msg := <-s.send:
+			s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := s.conn.WriteJSON(msg); err != nil {
+				s.close()
+				return
+			}
+		case <-heartbeat.C:
+			s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := s.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				s.close()
+				return
+			}
+			if err := s.conn.WriteJSON(WSMessage{Version: WebSocketProtocolVersion, Type: WSMessageHeartbeat}); err != nil {
+				s.close()
+				return
+			}
+		case <-s.done:
+			s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			s.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+			return
+		}
+	}
+}
+
+// reply queues a message for the client. It gives up once the session ends.
+func (s *wsSession) reply(msgType, id string, data interface{}) {
+	msg := WSMessage{Version: WebSocketProtocolVersion, Type: msgType, ID: id}
+	if data != nil {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			msg.Type = WSMessageError
+			encoded, _ = json.Marshal(WSError{Code: WSErrorInternal, Message: "failed to encode message"})
+		}
+		msg.Data = encoded
+	}
+
+	select {
+	case s.send <- msg:
+	case <-s.done:
+	}
+}
+
+func (s *wsSession) replyError(id, code, message string) {
+	s.reply(WSMessageError, id, WSError{Code: code, Message: message})
+}
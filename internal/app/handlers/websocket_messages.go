@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"golangwithgin/internal/domain"
+)
+
+// WebSocketProtocolVersion is the version of the JSON message protocol
+// spoken on /ws. Messages with another version are rejected.
+const WebSocketProtocolVersion = 1
+
+// Message types sent by clients
+const (
+	WSMessageSubmit      = "submit"
+	WSMessageSubscribe   = "subscribe"
+	WSMessageUnsubscribe = "unsubscribe"
+	WSMessagePing        = "ping"
+)
+
+// Message types sent by the server. Task events are sent with their event
+// type, such as "status".
+const (
+	WSMessageHello        = "hello"
+	WSMessageSubmitted    = "submitted"
+	WSMessageSubscribed   = "subscribed"
+	WSMessageUnsubscribed = "unsubscribed"
+	WSMessagePong         = "pong"
+	WSMessageHeartbeat    = "heartbeat"
+	WSMessageError        = "error"
+)
+
+// Error codes sent in error messages
+const (
+	WSErrorBadRequest    = "bad_request"
+	WSErrorInvalidTask   = "invalid_task"
+	WSErrorQueueFull     = "queue_full"
+	WSErrorQueueDraining = "queue_draining"
+	WSErrorNotFound      = "not_found"
+	WSErrorInternal      = "internal"
+)
+
+// WSMessage is the envelope of every message in either direction. Replies
+// carry the ID of the request they answer.
+type WSMessage struct {
+	Version int             `json:"v"`
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// WSHello is sent once the connection is established
+type WSHello struct {
+	UserID            uint `json:"user_id"`
+	HeartbeatInterval int  `json:"heartbeat_interval"` // seconds
+}
+
+// WSSubscribeRequest is the data of subscribe and unsubscribe messages
+type WSSubscribeRequest struct {
+	TaskIDs []uint `json:"task_ids"`
+}
+
+// WSSubscribed reports the current state of newly subscribed tasks
+type WSSubscribed struct {
+	Tasks    []*domain.Task `json:"tasks"`
+	NotFound []uint         `json:"not_found,omitempty"`
+}
+
+// WSError is the data of error messages
+type WSError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"golangwithgin/internal/app/middlewares"
+	"golangwithgin/internal/domain"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorkflowHandler struct {
+	workflowService domain.WorkflowService
+}
+
+func NewWorkflowHandler(workflowService domain.WorkflowService) *WorkflowHandler {
+	return &WorkflowHandler{
+		workflowService: workflowService,
+	}
+}
+
+// @Summary Submit a workflow
+// @Description Submit a group of tasks whose dependencies form a directed acyclic graph. Tasks name each other by key in depends_on; a task is blocked until the tasks it depends on complete. When one of them fails or is cancelled, the task is cancelled, failed or run anyway according to its dependency_policy. Unknown keys and dependency cycles are rejected. While the queue of any of its tasks has no room for them, the workflow is refused with 503 and a Retry-After header; while one of them is draining, with 503 alone.
+// @Tags workflows
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param workflow body WorkflowRequest true "Workflow details"
+// @Success 202 {object} domain.SwaggerWorkflow
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure 503 {object} domain.ErrorResponse "The task queue is full or draining"
+// @Header 503 {integer} Retry-After "Seconds to wait before submitting again"
+// @Router /workflows [post]
+func (h *WorkflowHandler) CreateWorkflow(c *gin.Context) {
+	var req WorkflowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := middlewares.GetUserID(c)
+	workflow := &domain.Workflow{UserID: userID, Name: req.Name}
+	tasks := make([]domain.WorkflowTask, len(req.Tasks))
+	for i, task := range req.Tasks {
+		tasks[i] = task.toWorkflowTask(userID)
+	}
+
+	err := h.workflowService.Submit(c.Request.Context(), workflow, tasks)
+	if errors.Is(err, domain.ErrInvalidWorkflow) || errors.Is(err, domain.ErrInvalidTask) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, domain.ErrQueueFull) {
+		rejectQueueFull(c, err)
+		return
+	}
+	if errors.Is(err, domain.ErrQueueDraining) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, workflow)
+}
+
+// @Summary Get a workflow
+// @Description Get a workflow owned by the caller with its tasks, their dependencies and an aggregate status. A workflow is pending until one of its tasks starts and running until all of them finish; it then failed if any task failed or died, was cancelled if any task was cancelled, and completed otherwise.
+// @Tags workflows
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Workflow ID"
+// @Success 200 {object} domain.SwaggerWorkflow
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Router /workflows/{id} [get]
+func (h *WorkflowHandler) GetWorkflow(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workflow ID"})
+		return
+	}
+
+	workflow, err := h.workflowService.Get(c.Request.Context(), middlewares.GetUserID(c), uint(id))
+	if errors.Is(err, domain.ErrWorkflowNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "workflow not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, workflow)
+}
+
+// Request/Response types
+type WorkflowRequest struct {
+	Name  string                `json:"name" example:"Nightly import"`
+	Tasks []WorkflowTaskRequest `json:"tasks" binding:"required,min=1,dive"`
+}
+
+type WorkflowTaskRequest struct {
+	// Key names the task within the workflow
+	Key string `json:"key" binding:"required" example:"extract"`
+	domain.TaskTemplate
+	RunAt            *time.Time              `json:"run_at,omitempty"`
+	DependsOn        []string                `json:"depends_on" example:"download"`
+	DependencyPolicy domain.DependencyPolicy `json:"dependency_policy" enums:"cancel,fail,ignore" example:"cancel"`
+}
+
+func (r WorkflowTaskRequest) toWorkflowTask(userID uint) domain.WorkflowTask {
+	task := r.TaskTemplate.NewTask(userID)
+	task.RunAt = r.RunAt
+	task.DependencyPolicy = r.DependencyPolicy
+	return domain.WorkflowTask{
+		Key:       r.Key,
+		Task:      task,
+		DependsOn: r.DependsOn,
+	}
+}
@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"golangwithgin/internal/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware restricts routes to administrators. The role is read
+// from the database on every request, so promotions and demotions apply
+// to tokens that were already issued.
+type AdminMiddleware struct {
+	userService domain.UserService
+}
+
+func NewAdminMiddleware(userService domain.UserService) *AdminMiddleware {
+	return &AdminMiddleware{
+		userService: userService,
+	}
+}
+
+// AdminRequired must run after AuthRequired
+func (m *AdminMiddleware) AdminRequired(c *gin.Context) {
+	user, err := m.userService.GetByID(GetUserID(c))
+	if err != nil || !user.IsAdmin() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		c.Abort()
+		return
+	}
+	c.Next()
+}
@@ -32,7 +32,29 @@ func (m *AuthMiddleware) AuthRequired(c *gin.Context) {
 		return
 	}
 
-	tokenString := parts[1]
+	m.authenticate(c, parts[1])
+}
+
+// AuthRequiredWebSocket works like AuthRequired but also accepts the token in
+// the access_token query parameter, because browsers cannot set headers on a
+// WebSocket handshake
+func (m *AuthMiddleware) AuthRequiredWebSocket(c *gin.Context) {
+	if c.GetHeader("Authorization") != "" {
+		m.AuthRequired(c)
+		return
+	}
+
+	tokenString := c.Query("access_token")
+	if tokenString == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header or access_token is required"})
+		c.Abort()
+		return
+	}
+	m.authenticate(c, tokenString)
+}
+
+// authenticate validates the token and stores the user ID in the context
+func (m *AuthMiddleware) authenticate(c *gin.Context, tokenString string) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		return []byte(m.jwtSecret), nil
 	})
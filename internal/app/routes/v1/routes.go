@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"expvar"
+
 	"github.com/gin-gonic/gin"
 	"golangwithgin/internal/app/handlers"
 	"golangwithgin/internal/app/middlewares"
@@ -10,13 +12,25 @@ func SetupRoutes(
 	router *gin.Engine,
 	userHandler *handlers.UserHandler,
 	taskHandler *handlers.TaskHandler,
+	taskEventHandler *handlers.TaskEventHandler,
+	webSocketHandler *handlers.WebSocketHandler,
+	webhookHandler *handlers.WebhookHandler,
+	scheduleHandler *handlers.ScheduleHandler,
+	workflowHandler *handlers.WorkflowHandler,
+	queueHandler *handlers.QueueHandler,
+	healthHandler *handlers.HealthHandler,
 	authMiddleware *middlewares.AuthMiddleware,
+	adminMiddleware *middlewares.AdminMiddleware,
 ) {
 	v1 := router.Group("/api/v1")
 	{
 		// Public routes
 		v1.POST("/register", userHandler.Register)
 		v1.POST("/login", userHandler.Login)
+		v1.GET("/health", healthHandler.Health)
+
+		// WebSocket API; browsers may pass the token as a query parameter
+		v1.GET("/ws", authMiddleware.AuthRequiredWebSocket, webSocketHandler.Serve)
 
 		// Protected routes
 		protected := v1.Group("/")
@@ -29,7 +43,47 @@ func SetupRoutes(
 			// Task routes
 			protected.POST("/tasks", taskHandler.CreateTask)
 			protected.GET("/tasks", taskHandler.GetAllTasks)
+			protected.GET("/tasks/dead", taskHandler.GetDeadTasks)
+			protected.GET("/tasks/events", taskEventHandler.StreamTasks)
 			protected.GET("/tasks/:id", taskHandler.GetTask)
+			protected.GET("/tasks/:id/result", taskHandler.GetTaskResult)
+			protected.GET("/tasks/:id/events", taskEventHandler.StreamTask)
+			protected.POST("/tasks/:id/cancel", taskHandler.CancelTask)
+			protected.POST("/tasks/:id/requeue", taskHandler.RequeueTask)
+
+			// Webhook routes
+			protected.POST("/webhooks", webhookHandler.CreateWebhook)
+			protected.GET("/webhooks", webhookHandler.ListWebhooks)
+			protected.GET("/webhooks/:id", webhookHandler.GetWebhook)
+			protected.PUT("/webhooks/:id", webhookHandler.UpdateWebhook)
+			protected.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+			protected.GET("/webhooks/:id/deliveries", webhookHandler.ListDeliveries)
+
+			// Schedule routes
+			protected.POST("/schedules", scheduleHandler.CreateSchedule)
+			protected.GET("/schedules", scheduleHandler.ListSchedules)
+			protected.GET("/schedules/:id", scheduleHandler.GetSchedule)
+			protected.PUT("/schedules/:id", scheduleHandler.UpdateSchedule)
+			protected.DELETE("/schedules/:id", scheduleHandler.DeleteSchedule)
+			protected.POST("/schedules/:id/pause", scheduleHandler.PauseSchedule)
+			protected.POST("/schedules/:id/resume", scheduleHandler.ResumeSchedule)
+
+			// Workflow routes
+			protected.POST("/workflows", workflowHandler.CreateWorkflow)
+			protected.GET("/workflows/:id", workflowHandler.GetWorkflow)
+		}
+
+		// Admin routes
+		admin := v1.Group("/")
+		admin.Use(authMiddleware.AuthRequired, adminMiddleware.AdminRequired)
+		{
+			admin.GET("/queues", queueHandler.ListQueues)
+			admin.POST("/queues/:name/pause", queueHandler.PauseQueue)
+			admin.POST("/queues/:name/resume", queueHandler.ResumeQueue)
+			admin.POST("/queues/:name/drain", queueHandler.DrainQueue)
+
+			// Worker pool metrics and other runtime variables
+			admin.GET("/debug/vars", gin.WrapH(expvar.Handler()))
 		}
 	}
 } 
\ No newline at end of file
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"golangwithgin/config"
 	"golangwithgin/internal/app/handlers"
@@ -10,15 +12,24 @@ import (
 	"golangwithgin/internal/service"
 	"golangwithgin/pkg/database"
 	"golangwithgin/internal/domain"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 type Server struct {
-	Router *gin.Engine
-	config *config.Config
-	logger *logrus.Logger
+	Router        *gin.Engine
+	config        *config.Config
+	logger        *logrus.Logger
+	httpServer    *http.Server
+	db            *gorm.DB
+	taskProcessor domain.TaskProcessor
+	taskEvents    domain.TaskEventBroker
+	webhooks      *service.WebhookDispatcher
+	idempotency   domain.IdempotencyService
+	scheduler     *service.Scheduler
 }
 
 // New creates a new server instance
@@ -35,6 +46,16 @@ func New(cfg *config.Config, logger *logrus.Logger) *Server {
 	if err := db.AutoMigrate(
 		&domain.User{},
 		&domain.Task{},
+		&domain.QueuedTask{},
+		&domain.QueueStateRecord{},
+		&domain.TaskResult{},
+		&domain.TaskEvent{},
+		&domain.Webhook{},
+		&domain.WebhookDelivery{},
+		&domain.IdempotencyKey{},
+		&domain.Schedule{},
+		&domain.Workflow{},
+		&domain.TaskDependency{},
 	); err != nil {
 		logger.Fatalf("Failed to run migrations: %v", err)
 	}
@@ -42,40 +63,175 @@ func New(cfg *config.Config, logger *logrus.Logger) *Server {
 	// Initialize repositories
 	userRepo := mysql.NewUserRepository(db)
 	taskRepo := mysql.NewTaskRepository(db)
+	taskQueue := mysql.NewTaskQueueRepository(db, cfg.Tasks.Priority.AgingInterval)
+	queueStates := mysql.NewQueueStateRepository(db)
+	taskResults := mysql.NewTaskResultRepository(db)
+	taskEventRepo := mysql.NewTaskEventRepository(db)
+	webhookRepo := mysql.NewWebhookRepository(db)
+	webhookDeliveryRepo := mysql.NewWebhookDeliveryRepository(db)
+	idempotencyKeyRepo := mysql.NewIdempotencyKeyRepository(db)
+	scheduleRepo := mysql.NewScheduleRepository(db)
+	workflowRepo := mysql.NewWorkflowRepository(db)
 
 	// Initialize task processor
-	taskProcessor := service.NewTaskProcessor()
+	processorConfig := service.ProcessorConfig{
+		Retry: service.RetryPolicy{
+			MaxAttempts:    cfg.Tasks.Retry.MaxAttempts,
+			InitialBackoff: cfg.Tasks.Retry.InitialBackoff,
+			MaxBackoff:     cfg.Tasks.Retry.MaxBackoff,
+			Multiplier:     cfg.Tasks.Retry.Multiplier,
+		},
+		Results: service.ResultPolicy{
+			MaxSize:   cfg.Tasks.Results.MaxSize,
+			Retention: cfg.Tasks.Results.Retention,
+		},
+		Timeout:          cfg.Tasks.Timeout,
+		ProgressInterval: cfg.Tasks.ProgressInterval,
+		MinWorkers:       cfg.Tasks.Workers.Min,
+		MaxWorkers:       cfg.Tasks.Workers.Max,
+		ScaleInterval:    cfg.Tasks.Workers.ScaleInterval,
+		ScaleUpWait:      cfg.Tasks.Workers.ScaleUpWait,
+		QueueCapacity:    cfg.Tasks.QueueCapacity,
+		UserConcurrency:  cfg.Tasks.UserConcurrency,
+		Queues:           make(map[string]service.QueueConfig, len(cfg.Tasks.Queues)),
+		Logger:           logger,
+	}
+	for name, queue := range cfg.Tasks.Queues {
+		processorConfig.Queues[name] = service.QueueConfig{
+			MinWorkers:      queue.MinWorkers,
+			MaxWorkers:      queue.Concurrency,
+			Capacity:        queue.Capacity,
+			RateLimit:       queue.RateLimit,
+			UserConcurrency: queue.UserConcurrency,
+		}
+	}
+	handlerRegistry := service.NewHandlerRegistry()
+	service.RegisterDefaultHandlers(handlerRegistry)
+	taskEvents := service.NewTaskEventBroker(taskEventRepo, cfg.Tasks.Events.Retention)
+	webhookDispatcher := service.NewWebhookDispatcher(webhookRepo, webhookDeliveryRepo, service.WebhookConfig{
+		Retry: service.RetryPolicy{
+			MaxAttempts:    cfg.Webhooks.Retry.MaxAttempts,
+			InitialBackoff: cfg.Webhooks.Retry.InitialBackoff,
+			MaxBackoff:     cfg.Webhooks.Retry.MaxBackoff,
+			Multiplier:     cfg.Webhooks.Retry.Multiplier,
+		},
+		Timeout:             cfg.Webhooks.Timeout,
+		Workers:             cfg.Webhooks.Workers,
+		AllowPrivateTargets: cfg.Webhooks.AllowPrivateTargets,
+	})
+	// Every status change goes through the dependency resolver, which starts
+	// or cascades to blocked tasks when their dependencies finish
+	publisher := service.NewDependencyResolver(taskRepo, service.NewPublisherGroup(taskEvents, webhookDispatcher))
+
+	// Reconcile tasks left unfinished by a previous run before workers start
+	// claiming them; dependents of tasks failed here are caught up below
+	recoverer, err := service.NewTaskRecoverer(taskRepo, taskQueue, publisher, cfg.Tasks.Recovery.Pending, cfg.Tasks.Recovery.Processing)
+	if err != nil {
+		logger.Fatalf("Failed to configure task recovery: %v", err)
+	}
+	summary, err := recoverer.Recover(context.Background())
+	if err != nil {
+		logger.Fatalf("Failed to recover orphaned tasks: %v", err)
+	}
+	logger.WithFields(logrus.Fields{
+		"found":    summary.Found,
+		"requeued": summary.Requeued,
+		"failed":   summary.Failed,
+		"ignored":  summary.Ignored,
+	}).Info("Recovered orphaned tasks")
+
+	taskProcessor := service.NewTaskProcessor(taskRepo, taskQueue, queueStates, taskResults, handlerRegistry, publisher, processorConfig)
+	publisher.SetProcessor(taskProcessor)
+	if err := publisher.ResolveBlocked(context.Background()); err != nil {
+		logger.Fatalf("Failed to resolve blocked tasks: %v", err)
+	}
 
 	// Initialize services
 	userService := service.NewUserService(userRepo, cfg.JWT.Secret)
-	taskService := service.NewTaskService(taskRepo, taskProcessor)
+	taskService := service.NewTaskService(taskRepo, taskResults, taskProcessor, taskQueue, handlerRegistry, publisher, processorConfig.Retry)
+	webhookService := service.NewWebhookService(webhookRepo, webhookDeliveryRepo, cfg.Webhooks.AllowPrivateTargets)
+	idempotencyService := service.NewIdempotencyService(idempotencyKeyRepo, cfg.Tasks.Idempotency.Window)
+	scheduleService := service.NewScheduleService(scheduleRepo, handlerRegistry)
+	workflowService := service.NewWorkflowService(workflowRepo, taskRepo, taskProcessor, handlerRegistry, publisher, processorConfig.Retry)
+
+	// Submit tasks for due schedules
+	scheduler := service.NewScheduler(scheduleRepo, taskService)
 
 	// Initialize handlers
 	userHandler := handlers.NewUserHandler(userService)
-	taskHandler := handlers.NewTaskHandler(taskService)
+	taskHandler := handlers.NewTaskHandler(taskService, idempotencyService)
+	taskEventHandler := handlers.NewTaskEventHandler(taskService, taskEvents)
+	webSocketHandler := handlers.NewWebSocketHandler(taskService, taskEvents)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	scheduleHandler := handlers.NewScheduleHandler(scheduleService)
+	workflowHandler := handlers.NewWorkflowHandler(workflowService)
+	queueHandler := handlers.NewQueueHandler(taskProcessor)
+	healthHandler := handlers.NewHealthHandler(taskProcessor)
 
 	// Initialize middlewares
 	authMiddleware := middlewares.NewAuthMiddleware(cfg.JWT.Secret)
+	adminMiddleware := middlewares.NewAdminMiddleware(userService)
 
 	// Setup routes
-	v1.SetupRoutes(router, userHandler, taskHandler, authMiddleware)
+	v1.SetupRoutes(router, userHandler, taskHandler, taskEventHandler, webSocketHandler, webhookHandler, scheduleHandler, workflowHandler, queueHandler, healthHandler, authMiddleware, adminMiddleware)
 
 	return &Server{
-		Router: router,
-		config: cfg,
-		logger: logger,
+		Router:        router,
+		config:        cfg,
+		logger:        logger,
+		httpServer:    &http.Server{Handler: router},
+		db:            db,
+		taskProcessor: taskProcessor,
+		taskEvents:    taskEvents,
+		webhooks:      webhookDispatcher,
+		idempotency:   idempotencyService,
+		scheduler:     scheduler,
 	}
 }
 
-// Start starts the server
+// Start starts the server and blocks until it is shut down
 func (s *Server) Start() error {
-	addr := fmt.Sprintf(":%s", s.config.Server.Port)
-	s.logger.Infof("Starting server on %s", addr)
-	return s.Router.Run(addr)
+	s.httpServer.Addr = fmt.Sprintf(":%s", s.config.Server.Port)
+	s.logger.Infof("Starting server on %s", s.httpServer.Addr)
+	if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
 
-func (s *Server) Shutdown() {
-	// No need to implement shutdown logic as the server is managed by gin
+// Shutdown drains in-flight requests, waits for running tasks to record
+// their final status and closes the database pool. Once ctx ends, the
+// remaining requests and tasks are cut short.
+func (s *Server) Shutdown(ctx context.Context) error {
+	// Event streams and WebSocket sessions never go idle, so end them before
+	// draining
+	s.taskEvents.Close()
+
+	// The rest of the shutdown still runs if draining times out, so that
+	// tasks and deliveries are stopped and the pool is closed
+	s.logger.Info("Draining HTTP connections")
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		s.logger.WithError(err).Error("Failed to drain HTTP server")
+	}
+
+	s.logger.Info("Stopping scheduler")
+	s.scheduler.Shutdown()
+
+	s.logger.Info("Stopping task processor")
+	if err := s.taskProcessor.Shutdown(ctx); err != nil {
+		s.logger.WithError(err).Warn("Stopped running tasks; they run again once their leases expire")
+	}
+
+	// Deliveries still pending are stored and resume on the next start
+	s.logger.Info("Stopping webhook dispatcher")
+	s.webhooks.Shutdown()
+	s.idempotency.Close()
+
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
 }
 
 // GetRouter returns the server's router instance
@@ -8,4 +8,35 @@ var (
 	ErrUserExists        = errors.New("user already exists")
 	ErrTaskNotFound      = errors.New("task not found")
 	ErrInvalidTaskStatus = errors.New("invalid task status")
-) 
\ No newline at end of file
+	ErrQueueEmpty        = errors.New("task queue is empty")
+	ErrQueueFull         = errors.New("task queue is full")
+	ErrQueueDraining     = errors.New("task queue is draining")
+	ErrQueueNotFound     = errors.New("task queue not found")
+	ErrLeaseLost         = errors.New("task lease lost")
+	ErrTaskNotQueued     = errors.New("task is not queued")
+	ErrInvalidCursor     = errors.New("invalid pagination cursor")
+	ErrInvalidTaskFilter = errors.New("invalid task filter")
+	ErrTaskCancelled     = errors.New("task cancelled")
+	ErrTaskTimedOut      = errors.New("task timed out")
+	ErrTaskFinished      = errors.New("task already finished")
+	ErrTaskNotDead       = errors.New("only dead tasks can be requeued")
+	ErrInvalidTask       = errors.New("invalid task")
+	ErrTaskNotCompleted  = errors.New("task has not completed")
+	ErrResultNotFound    = errors.New("task result not found")
+	ErrResultTooLarge    = errors.New("task result too large")
+	ErrWebhookNotFound   = errors.New("webhook not found")
+	ErrInvalidWebhook    = errors.New("invalid webhook")
+
+	ErrWorkflowNotFound = errors.New("workflow not found")
+	ErrInvalidWorkflow  = errors.New("invalid workflow")
+
+	ErrScheduleNotFound = errors.New("schedule not found")
+	ErrInvalidSchedule  = errors.New("invalid schedule")
+	ErrScheduleNotDue   = errors.New("schedule is not due")
+
+	ErrInvalidIdempotencyKey    = errors.New("invalid idempotency key")
+	ErrIdempotencyKeyExists     = errors.New("idempotency key already exists")
+	ErrIdempotencyKeyNotFound   = errors.New("idempotency key not found")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is in progress")
+)
\ No newline at end of file
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotencyKey records a request made with an Idempotency-Key header so a
+// retry with the same key replays the original response instead of repeating
+// the request. A key is reserved before the request runs and holds the
+// response once it has completed.
+type IdempotencyKey struct {
+	ID           uint   `gorm:"primaryKey"`
+	UserID       uint   `gorm:"uniqueIndex:idx_idempotency_keys_user_key,priority:1"`
+	Key          string `gorm:"column:idempotency_key;size:255;uniqueIndex:idx_idempotency_keys_user_key,priority:2"`
+	RequestHash  string `gorm:"size:64"`
+	ResponseCode int
+	ResponseBody JSON      `gorm:"type:json"`
+	ExpiresAt    time.Time `gorm:"index"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Completed reports whether the response of the request has been stored
+func (k *IdempotencyKey) Completed() bool {
+	return k.ResponseCode != 0
+}
+
+type IdempotencyKeyRepository interface {
+	// Create stores a new key, or returns ErrIdempotencyKeyExists if the user
+	// already has it
+	Create(ctx context.Context, key *IdempotencyKey) error
+	Find(ctx context.Context, userID uint, key string) (*IdempotencyKey, error)
+	Update(ctx context.Context, key *IdempotencyKey) error
+	Delete(ctx context.Context, id uint) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type IdempotencyService interface {
+	// Begin reserves the key for a request. If the key was used before with
+	// the same request it returns the earlier record, which holds the response
+	// to replay once completed.
+	Begin(ctx context.Context, userID uint, key string, request []byte) (*IdempotencyKey, error)
+	// Complete stores the response of a reserved request
+	Complete(ctx context.Context, key *IdempotencyKey, code int, body []byte) error
+	// Release frees a reserved key whose request failed so it can be retried
+	Release(ctx context.Context, key *IdempotencyKey) error
+	// Close stops purging expired keys
+	Close()
+}
@@ -2,4 +2,14 @@ package mocks
 
 //go:generate mockgen -destination=task_repository_mock.go -package=mocks golangwithgin/internal/domain TaskRepository
 //go:generate mockgen -destination=task_processor_mock.go -package=mocks golangwithgin/internal/domain TaskProcessor
+//go:generate mockgen -destination=task_queue_mock.go -package=mocks golangwithgin/internal/domain TaskQueue
+//go:generate mockgen -destination=queue_state_repository_mock.go -package=mocks golangwithgin/internal/domain QueueStateRepository
+//go:generate mockgen -destination=task_result_repository_mock.go -package=mocks golangwithgin/internal/domain TaskResultRepository
+//go:generate mockgen -destination=task_event_repository_mock.go -package=mocks golangwithgin/internal/domain TaskEventRepository
+//go:generate mockgen -destination=task_event_publisher_mock.go -package=mocks golangwithgin/internal/domain TaskEventPublisher
+//go:generate mockgen -destination=webhook_repository_mock.go -package=mocks golangwithgin/internal/domain WebhookRepository
+//go:generate mockgen -destination=webhook_delivery_repository_mock.go -package=mocks golangwithgin/internal/domain WebhookDeliveryRepository
+//go:generate mockgen -destination=idempotency_key_repository_mock.go -package=mocks golangwithgin/internal/domain IdempotencyKeyRepository
+//go:generate mockgen -destination=schedule_repository_mock.go -package=mocks golangwithgin/internal/domain ScheduleRepository
+//go:generate mockgen -destination=workflow_repository_mock.go -package=mocks golangwithgin/internal/domain WorkflowRepository
 //go:generate mockgen -destination=task_service_mock.go -package=mocks golangwithgin/internal/domain TaskService 
\ No newline at end of file
@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: golangwithgin/internal/domain (interfaces: QueueStateRepository)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	domain "golangwithgin/internal/domain"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockQueueStateRepository is a mock of QueueStateRepository interface.
+type MockQueueStateRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockQueueStateRepositoryMockRecorder
+}
+
+// MockQueueStateRepositoryMockRecorder is the mock recorder for MockQueueStateRepository.
+type MockQueueStateRepositoryMockRecorder struct {
+	mock *MockQueueStateRepository
+}
+
+// NewMockQueueStateRepository creates a new mock instance.
+func NewMockQueueStateRepository(ctrl *gomock.Controller) *MockQueueStateRepository {
+	mock := &MockQueueStateRepository{ctrl: ctrl}
+	mock.recorder = &MockQueueStateRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQueueStateRepository) EXPECT() *MockQueueStateRepositoryMockRecorder {
+	return m.recorder
+}
+
+// All mocks base method.
+func (m *MockQueueStateRepository) All(arg0 context.Context) (map[string]domain.QueueState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "All", arg0)
+	ret0, _ := ret[0].(map[string]domain.QueueState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// All indicates an expected call of All.
+func (mr *MockQueueStateRepositoryMockRecorder) All(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "All", reflect.TypeOf((*MockQueueStateRepository)(nil).All), arg0)
+}
+
+// Set mocks base method.
+func (m *MockQueueStateRepository) Set(arg0 context.Context, arg1 string, arg2 domain.QueueState) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockQueueStateRepositoryMockRecorder) Set(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockQueueStateRepository)(nil).Set), arg0, arg1, arg2)
+}
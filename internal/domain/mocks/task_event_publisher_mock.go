@@ -0,0 +1,59 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: golangwithgin/internal/domain (interfaces: TaskEventPublisher)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	domain "golangwithgin/internal/domain"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTaskEventPublisher is a mock of TaskEventPublisher interface.
+type MockTaskEventPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskEventPublisherMockRecorder
+}
+
+// MockTaskEventPublisherMockRecorder is the mock recorder for MockTaskEventPublisher.
+type MockTaskEventPublisherMockRecorder struct {
+	mock *MockTaskEventPublisher
+}
+
+// NewMockTaskEventPublisher creates a new mock instance.
+func NewMockTaskEventPublisher(ctrl *gomock.Controller) *MockTaskEventPublisher {
+	mock := &MockTaskEventPublisher{ctrl: ctrl}
+	mock.recorder = &MockTaskEventPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskEventPublisher) EXPECT() *MockTaskEventPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockTaskEventPublisher) Publish(arg0 *domain.Task) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Publish", arg0)
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockTaskEventPublisherMockRecorder) Publish(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockTaskEventPublisher)(nil).Publish), arg0)
+}
+
+// PublishProgress mocks base method.
+func (m *MockTaskEventPublisher) PublishProgress(arg0 *domain.Task) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PublishProgress", arg0)
+}
+
+// PublishProgress indicates an expected call of PublishProgress.
+func (mr *MockTaskEventPublisherMockRecorder) PublishProgress(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishProgress", reflect.TypeOf((*MockTaskEventPublisher)(nil).PublishProgress), arg0)
+}
@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: golangwithgin/internal/domain (interfaces: TaskEventRepository)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	domain "golangwithgin/internal/domain"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTaskEventRepository is a mock of TaskEventRepository interface.
+type MockTaskEventRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskEventRepositoryMockRecorder
+}
+
+// MockTaskEventRepositoryMockRecorder is the mock recorder for MockTaskEventRepository.
+type MockTaskEventRepositoryMockRecorder struct {
+	mock *MockTaskEventRepository
+}
+
+// NewMockTaskEventRepository creates a new mock instance.
+func NewMockTaskEventRepository(ctrl *gomock.Controller) *MockTaskEventRepository {
+	mock := &MockTaskEventRepository{ctrl: ctrl}
+	mock.recorder = &MockTaskEventRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskEventRepository) EXPECT() *MockTaskEventRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Append mocks base method.
+func (m *MockTaskEventRepository) Append(arg0 context.Context, arg1 *domain.TaskEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Append", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Append indicates an expected call of Append.
+func (mr *MockTaskEventRepositoryMockRecorder) Append(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*MockTaskEventRepository)(nil).Append), arg0, arg1)
+}
+
+// DeleteBefore mocks base method.
+func (m *MockTaskEventRepository) DeleteBefore(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBefore", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteBefore indicates an expected call of DeleteBefore.
+func (mr *MockTaskEventRepositoryMockRecorder) DeleteBefore(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBefore", reflect.TypeOf((*MockTaskEventRepository)(nil).DeleteBefore), arg0, arg1)
+}
+
+// LastID mocks base method.
+func (m *MockTaskEventRepository) LastID(arg0 context.Context, arg1 uint) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastID", arg0, arg1)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastID indicates an expected call of LastID.
+func (mr *MockTaskEventRepositoryMockRecorder) LastID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastID", reflect.TypeOf((*MockTaskEventRepository)(nil).LastID), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockTaskEventRepository) List(arg0 context.Context, arg1 domain.TaskEventFilter) ([]*domain.TaskEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.TaskEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockTaskEventRepositoryMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTaskEventRepository)(nil).List), arg0, arg1)
+}
@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	domain "golangwithgin/internal/domain"
 	reflect "reflect"
 
@@ -34,28 +35,132 @@ func (m *MockTaskProcessor) EXPECT() *MockTaskProcessorMockRecorder {
 	return m.recorder
 }
 
+// Admit mocks base method.
+func (m *MockTaskProcessor) Admit(arg0 context.Context, arg1 []*domain.Task) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Admit", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Admit indicates an expected call of Admit.
+func (mr *MockTaskProcessorMockRecorder) Admit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Admit", reflect.TypeOf((*MockTaskProcessor)(nil).Admit), arg0, arg1)
+}
+
+// Cancel mocks base method.
+func (m *MockTaskProcessor) Cancel(arg0 context.Context, arg1 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cancel", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Cancel indicates an expected call of Cancel.
+func (mr *MockTaskProcessorMockRecorder) Cancel(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockTaskProcessor)(nil).Cancel), arg0, arg1)
+}
+
+// Drain mocks base method.
+func (m *MockTaskProcessor) Drain(arg0 context.Context, arg1 string) (domain.QueueState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Drain", arg0, arg1)
+	ret0, _ := ret[0].(domain.QueueState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Drain indicates an expected call of Drain.
+func (mr *MockTaskProcessorMockRecorder) Drain(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Drain", reflect.TypeOf((*MockTaskProcessor)(nil).Drain), arg0, arg1)
+}
+
+// Pause mocks base method.
+func (m *MockTaskProcessor) Pause(arg0 context.Context, arg1 string) (domain.QueueState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pause", arg0, arg1)
+	ret0, _ := ret[0].(domain.QueueState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Pause indicates an expected call of Pause.
+func (mr *MockTaskProcessorMockRecorder) Pause(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pause", reflect.TypeOf((*MockTaskProcessor)(nil).Pause), arg0, arg1)
+}
+
 // Process mocks base method.
-func (m *MockTaskProcessor) Process(arg0 *domain.Task) error {
+func (m *MockTaskProcessor) Process(arg0 context.Context, arg1 *domain.Task) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Process", arg0)
+	ret := m.ctrl.Call(m, "Process", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Process indicates an expected call of Process.
-func (mr *MockTaskProcessorMockRecorder) Process(arg0 interface{}) *gomock.Call {
+func (mr *MockTaskProcessorMockRecorder) Process(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Process", reflect.TypeOf((*MockTaskProcessor)(nil).Process), arg0, arg1)
+}
+
+// Queues mocks base method.
+func (m *MockTaskProcessor) Queues(arg0 context.Context) ([]*domain.QueueStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Queues", arg0)
+	ret0, _ := ret[0].([]*domain.QueueStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Queues indicates an expected call of Queues.
+func (mr *MockTaskProcessorMockRecorder) Queues(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Queues", reflect.TypeOf((*MockTaskProcessor)(nil).Queues), arg0)
+}
+
+// Resume mocks base method.
+func (m *MockTaskProcessor) Resume(arg0 context.Context, arg1 string) (domain.QueueState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resume", arg0, arg1)
+	ret0, _ := ret[0].(domain.QueueState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Resume indicates an expected call of Resume.
+func (mr *MockTaskProcessorMockRecorder) Resume(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Process", reflect.TypeOf((*MockTaskProcessor)(nil).Process), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resume", reflect.TypeOf((*MockTaskProcessor)(nil).Resume), arg0, arg1)
 }
 
 // Shutdown mocks base method.
-func (m *MockTaskProcessor) Shutdown() {
+func (m *MockTaskProcessor) Shutdown(arg0 context.Context) error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Shutdown")
+	ret := m.ctrl.Call(m, "Shutdown", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // Shutdown indicates an expected call of Shutdown.
-func (mr *MockTaskProcessorMockRecorder) Shutdown() *gomock.Call {
+func (mr *MockTaskProcessorMockRecorder) Shutdown(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockTaskProcessor)(nil).Shutdown), arg0)
+}
+
+// States mocks base method.
+func (m *MockTaskProcessor) States() map[string]domain.QueueState {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "States")
+	ret0, _ := ret[0].(map[string]domain.QueueState)
+	return ret0
+}
+
+// States indicates an expected call of States.
+func (mr *MockTaskProcessorMockRecorder) States() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockTaskProcessor)(nil).Shutdown))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "States", reflect.TypeOf((*MockTaskProcessor)(nil).States))
 }
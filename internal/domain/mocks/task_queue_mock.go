@@ -0,0 +1,197 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: golangwithgin/internal/domain (interfaces: TaskQueue)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	domain "golangwithgin/internal/domain"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTaskQueue is a mock of TaskQueue interface.
+type MockTaskQueue struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskQueueMockRecorder
+}
+
+// MockTaskQueueMockRecorder is the mock recorder for MockTaskQueue.
+type MockTaskQueueMockRecorder struct {
+	mock *MockTaskQueue
+}
+
+// NewMockTaskQueue creates a new mock instance.
+func NewMockTaskQueue(ctrl *gomock.Controller) *MockTaskQueue {
+	mock := &MockTaskQueue{ctrl: ctrl}
+	mock.recorder = &MockTaskQueueMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskQueue) EXPECT() *MockTaskQueueMockRecorder {
+	return m.recorder
+}
+
+// Claim mocks base method.
+func (m *MockTaskQueue) Claim(arg0 context.Context, arg1 string, arg2 uint, arg3 string, arg4 time.Duration) (*domain.QueuedTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Claim", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*domain.QueuedTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Claim indicates an expected call of Claim.
+func (mr *MockTaskQueueMockRecorder) Claim(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockTaskQueue)(nil).Claim), arg0, arg1, arg2, arg3, arg4)
+}
+
+// Complete mocks base method.
+func (m *MockTaskQueue) Complete(arg0 context.Context, arg1 *domain.QueuedTask) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockTaskQueueMockRecorder) Complete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockTaskQueue)(nil).Complete), arg0, arg1)
+}
+
+// Counts mocks base method.
+func (m *MockTaskQueue) Counts(arg0 context.Context) (map[string]domain.QueueCounts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Counts", arg0)
+	ret0, _ := ret[0].(map[string]domain.QueueCounts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Counts indicates an expected call of Counts.
+func (mr *MockTaskQueueMockRecorder) Counts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Counts", reflect.TypeOf((*MockTaskQueue)(nil).Counts), arg0)
+}
+
+// Depth mocks base method.
+func (m *MockTaskQueue) Depth(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Depth", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Depth indicates an expected call of Depth.
+func (mr *MockTaskQueueMockRecorder) Depth(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Depth", reflect.TypeOf((*MockTaskQueue)(nil).Depth), arg0, arg1)
+}
+
+// Enqueue mocks base method.
+func (m *MockTaskQueue) Enqueue(arg0 context.Context, arg1 *domain.Task) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockTaskQueueMockRecorder) Enqueue(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockTaskQueue)(nil).Enqueue), arg0, arg1)
+}
+
+// ExtendLease mocks base method.
+func (m *MockTaskQueue) ExtendLease(arg0 context.Context, arg1 *domain.QueuedTask, arg2 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtendLease", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExtendLease indicates an expected call of ExtendLease.
+func (mr *MockTaskQueueMockRecorder) ExtendLease(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtendLease", reflect.TypeOf((*MockTaskQueue)(nil).ExtendLease), arg0, arg1, arg2)
+}
+
+// FindByTaskID mocks base method.
+func (m *MockTaskQueue) FindByTaskID(arg0 context.Context, arg1 uint) (*domain.QueuedTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByTaskID", arg0, arg1)
+	ret0, _ := ret[0].(*domain.QueuedTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByTaskID indicates an expected call of FindByTaskID.
+func (mr *MockTaskQueueMockRecorder) FindByTaskID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByTaskID", reflect.TypeOf((*MockTaskQueue)(nil).FindByTaskID), arg0, arg1)
+}
+
+// Positions mocks base method.
+func (m *MockTaskQueue) Positions(arg0 context.Context, arg1 []uint) (map[uint]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Positions", arg0, arg1)
+	ret0, _ := ret[0].(map[uint]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Positions indicates an expected call of Positions.
+func (mr *MockTaskQueueMockRecorder) Positions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Positions", reflect.TypeOf((*MockTaskQueue)(nil).Positions), arg0, arg1)
+}
+
+// Remove mocks base method.
+func (m *MockTaskQueue) Remove(arg0 context.Context, arg1 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockTaskQueueMockRecorder) Remove(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockTaskQueue)(nil).Remove), arg0, arg1)
+}
+
+// Retry mocks base method.
+func (m *MockTaskQueue) Retry(arg0 context.Context, arg1 *domain.QueuedTask, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Retry", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Retry indicates an expected call of Retry.
+func (mr *MockTaskQueueMockRecorder) Retry(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Retry", reflect.TypeOf((*MockTaskQueue)(nil).Retry), arg0, arg1, arg2)
+}
+
+// Shares mocks base method.
+func (m *MockTaskQueue) Shares(arg0 context.Context, arg1 string) ([]domain.UserShare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shares", arg0, arg1)
+	ret0, _ := ret[0].([]domain.UserShare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Shares indicates an expected call of Shares.
+func (mr *MockTaskQueueMockRecorder) Shares(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shares", reflect.TypeOf((*MockTaskQueue)(nil).Shares), arg0, arg1)
+}
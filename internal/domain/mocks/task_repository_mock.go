@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	domain "golangwithgin/internal/domain"
 	reflect "reflect"
 
@@ -35,59 +36,158 @@ func (m *MockTaskRepository) EXPECT() *MockTaskRepositoryMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockTaskRepository) Create(arg0 *domain.Task) error {
+func (m *MockTaskRepository) Create(arg0 context.Context, arg1 *domain.Task) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", arg0)
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockTaskRepositoryMockRecorder) Create(arg0 interface{}) *gomock.Call {
+func (mr *MockTaskRepositoryMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTaskRepository)(nil).Create), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTaskRepository)(nil).Create), arg0, arg1)
 }
 
-// FindAll mocks base method.
-func (m *MockTaskRepository) FindAll() ([]*domain.Task, error) {
+// FindBlockedDependents mocks base method.
+func (m *MockTaskRepository) FindBlockedDependents(arg0 context.Context, arg1 uint) ([]*domain.Task, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindAll")
+	ret := m.ctrl.Call(m, "FindBlockedDependents", arg0, arg1)
 	ret0, _ := ret[0].([]*domain.Task)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FindAll indicates an expected call of FindAll.
-func (mr *MockTaskRepositoryMockRecorder) FindAll() *gomock.Call {
+// FindBlockedDependents indicates an expected call of FindBlockedDependents.
+func (mr *MockTaskRepositoryMockRecorder) FindBlockedDependents(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockTaskRepository)(nil).FindAll))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBlockedDependents", reflect.TypeOf((*MockTaskRepository)(nil).FindBlockedDependents), arg0, arg1)
 }
 
 // FindByID mocks base method.
-func (m *MockTaskRepository) FindByID(arg0 uint) (*domain.Task, error) {
+func (m *MockTaskRepository) FindByID(arg0 context.Context, arg1 uint) (*domain.Task, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindByID", arg0)
+	ret := m.ctrl.Call(m, "FindByID", arg0, arg1)
 	ret0, _ := ret[0].(*domain.Task)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindByID indicates an expected call of FindByID.
-func (mr *MockTaskRepositoryMockRecorder) FindByID(arg0 interface{}) *gomock.Call {
+func (mr *MockTaskRepositoryMockRecorder) FindByID(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockTaskRepository)(nil).FindByID), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockTaskRepository)(nil).FindByID), arg0, arg1)
+}
+
+// FindByIDForUser mocks base method.
+func (m *MockTaskRepository) FindByIDForUser(arg0 context.Context, arg1, arg2 uint) (*domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIDForUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIDForUser indicates an expected call of FindByIDForUser.
+func (mr *MockTaskRepositoryMockRecorder) FindByIDForUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDForUser", reflect.TypeOf((*MockTaskRepository)(nil).FindByIDForUser), arg0, arg1, arg2)
+}
+
+// FindByIDsForUser mocks base method.
+func (m *MockTaskRepository) FindByIDsForUser(arg0 context.Context, arg1 []uint, arg2 uint) ([]*domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIDsForUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIDsForUser indicates an expected call of FindByIDsForUser.
+func (mr *MockTaskRepositoryMockRecorder) FindByIDsForUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDsForUser", reflect.TypeOf((*MockTaskRepository)(nil).FindByIDsForUser), arg0, arg1, arg2)
+}
+
+// FindByStatus mocks base method.
+func (m *MockTaskRepository) FindByStatus(arg0 context.Context, arg1 ...domain.TaskStatus) ([]*domain.Task, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindByStatus", varargs...)
+	ret0, _ := ret[0].([]*domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByStatus indicates an expected call of FindByStatus.
+func (mr *MockTaskRepositoryMockRecorder) FindByStatus(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByStatus", reflect.TypeOf((*MockTaskRepository)(nil).FindByStatus), varargs...)
+}
+
+// FindDependencies mocks base method.
+func (m *MockTaskRepository) FindDependencies(arg0 context.Context, arg1 uint) ([]*domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDependencies", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDependencies indicates an expected call of FindDependencies.
+func (mr *MockTaskRepositoryMockRecorder) FindDependencies(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDependencies", reflect.TypeOf((*MockTaskRepository)(nil).FindDependencies), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockTaskRepository) List(arg0 context.Context, arg1 domain.TaskFilter) (*domain.TaskPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].(*domain.TaskPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockTaskRepositoryMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTaskRepository)(nil).List), arg0, arg1)
 }
 
 // Update mocks base method.
-func (m *MockTaskRepository) Update(arg0 *domain.Task) error {
+func (m *MockTaskRepository) Update(arg0 context.Context, arg1 *domain.Task, arg2 ...string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", arg0)
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Update", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockTaskRepositoryMockRecorder) Update(arg0 interface{}) *gomock.Call {
+func (mr *MockTaskRepositoryMockRecorder) Update(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTaskRepository)(nil).Update), varargs...)
+}
+
+// UpdateProgress mocks base method.
+func (m *MockTaskRepository) UpdateProgress(arg0 context.Context, arg1 *domain.Task) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProgress", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProgress indicates an expected call of UpdateProgress.
+func (mr *MockTaskRepositoryMockRecorder) UpdateProgress(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTaskRepository)(nil).Update), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProgress", reflect.TypeOf((*MockTaskRepository)(nil).UpdateProgress), arg0, arg1)
 }
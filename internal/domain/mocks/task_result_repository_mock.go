@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: golangwithgin/internal/domain (interfaces: TaskResultRepository)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	domain "golangwithgin/internal/domain"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTaskResultRepository is a mock of TaskResultRepository interface.
+type MockTaskResultRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskResultRepositoryMockRecorder
+}
+
+// MockTaskResultRepositoryMockRecorder is the mock recorder for MockTaskResultRepository.
+type MockTaskResultRepositoryMockRecorder struct {
+	mock *MockTaskResultRepository
+}
+
+// NewMockTaskResultRepository creates a new mock instance.
+func NewMockTaskResultRepository(ctrl *gomock.Controller) *MockTaskResultRepository {
+	mock := &MockTaskResultRepository{ctrl: ctrl}
+	mock.recorder = &MockTaskResultRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskResultRepository) EXPECT() *MockTaskResultRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpired mocks base method.
+func (m *MockTaskResultRepository) DeleteExpired(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockTaskResultRepositoryMockRecorder) DeleteExpired(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockTaskResultRepository)(nil).DeleteExpired), arg0, arg1)
+}
+
+// FindByTaskID mocks base method.
+func (m *MockTaskResultRepository) FindByTaskID(arg0 context.Context, arg1 uint) (*domain.TaskResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByTaskID", arg0, arg1)
+	ret0, _ := ret[0].(*domain.TaskResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByTaskID indicates an expected call of FindByTaskID.
+func (mr *MockTaskResultRepositoryMockRecorder) FindByTaskID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByTaskID", reflect.TypeOf((*MockTaskResultRepository)(nil).FindByTaskID), arg0, arg1)
+}
+
+// Save mocks base method.
+func (m *MockTaskResultRepository) Save(arg0 context.Context, arg1 *domain.TaskResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockTaskResultRepositoryMockRecorder) Save(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockTaskResultRepository)(nil).Save), arg0, arg1)
+}
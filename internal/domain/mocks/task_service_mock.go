@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	domain "golangwithgin/internal/domain"
 	reflect "reflect"
 
@@ -34,46 +35,91 @@ func (m *MockTaskService) EXPECT() *MockTaskServiceMockRecorder {
 	return m.recorder
 }
 
+// CancelTask mocks base method.
+func (m *MockTaskService) CancelTask(arg0 context.Context, arg1, arg2 uint) (*domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelTask", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelTask indicates an expected call of CancelTask.
+func (mr *MockTaskServiceMockRecorder) CancelTask(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelTask", reflect.TypeOf((*MockTaskService)(nil).CancelTask), arg0, arg1, arg2)
+}
+
 // GetAllTasks mocks base method.
-func (m *MockTaskService) GetAllTasks() ([]*domain.Task, error) {
+func (m *MockTaskService) GetAllTasks(arg0 context.Context, arg1 domain.TaskFilter) (*domain.TaskPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllTasks")
-	ret0, _ := ret[0].([]*domain.Task)
+	ret := m.ctrl.Call(m, "GetAllTasks", arg0, arg1)
+	ret0, _ := ret[0].(*domain.TaskPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllTasks indicates an expected call of GetAllTasks.
-func (mr *MockTaskServiceMockRecorder) GetAllTasks() *gomock.Call {
+func (mr *MockTaskServiceMockRecorder) GetAllTasks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllTasks", reflect.TypeOf((*MockTaskService)(nil).GetAllTasks), arg0, arg1)
+}
+
+// GetTaskResult mocks base method.
+func (m *MockTaskService) GetTaskResult(arg0 context.Context, arg1, arg2 uint) (*domain.TaskResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskResult", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.TaskResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskResult indicates an expected call of GetTaskResult.
+func (mr *MockTaskServiceMockRecorder) GetTaskResult(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllTasks", reflect.TypeOf((*MockTaskService)(nil).GetAllTasks))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskResult", reflect.TypeOf((*MockTaskService)(nil).GetTaskResult), arg0, arg1, arg2)
 }
 
 // GetTaskStatus mocks base method.
-func (m *MockTaskService) GetTaskStatus(arg0 uint) (*domain.Task, error) {
+func (m *MockTaskService) GetTaskStatus(arg0 context.Context, arg1, arg2 uint) (*domain.Task, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTaskStatus", arg0)
+	ret := m.ctrl.Call(m, "GetTaskStatus", arg0, arg1, arg2)
 	ret0, _ := ret[0].(*domain.Task)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetTaskStatus indicates an expected call of GetTaskStatus.
-func (mr *MockTaskServiceMockRecorder) GetTaskStatus(arg0 interface{}) *gomock.Call {
+func (mr *MockTaskServiceMockRecorder) GetTaskStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskStatus", reflect.TypeOf((*MockTaskService)(nil).GetTaskStatus), arg0, arg1, arg2)
+}
+
+// RequeueTask mocks base method.
+func (m *MockTaskService) RequeueTask(arg0 context.Context, arg1, arg2 uint) (*domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueTask", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueTask indicates an expected call of RequeueTask.
+func (mr *MockTaskServiceMockRecorder) RequeueTask(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskStatus", reflect.TypeOf((*MockTaskService)(nil).GetTaskStatus), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueTask", reflect.TypeOf((*MockTaskService)(nil).RequeueTask), arg0, arg1, arg2)
 }
 
 // SubmitTask mocks base method.
-func (m *MockTaskService) SubmitTask(arg0 *domain.Task) error {
+func (m *MockTaskService) SubmitTask(arg0 context.Context, arg1 *domain.Task) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SubmitTask", arg0)
+	ret := m.ctrl.Call(m, "SubmitTask", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SubmitTask indicates an expected call of SubmitTask.
-func (mr *MockTaskServiceMockRecorder) SubmitTask(arg0 interface{}) *gomock.Call {
+func (mr *MockTaskServiceMockRecorder) SubmitTask(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitTask", reflect.TypeOf((*MockTaskService)(nil).SubmitTask), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitTask", reflect.TypeOf((*MockTaskService)(nil).SubmitTask), arg0, arg1)
 }
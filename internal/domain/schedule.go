@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// TaskTemplate describes the task a schedule submits on every run
+type TaskTemplate struct {
+	Title       string `json:"title" gorm:"size:255"`
+	Description string `json:"description" gorm:"type:text"`
+	Type        string `json:"type" gorm:"size:100"`
+	Queue       string `json:"queue,omitempty" gorm:"size:100"`
+	Payload     JSON   `json:"payload,omitempty" gorm:"type:json"`
+	Priority    int    `json:"priority"`
+	MaxAttempts int    `json:"max_attempts"`
+	Timeout     int    `json:"timeout,omitempty"`
+}
+
+// NewTask returns a task for the user built from the template
+func (t TaskTemplate) NewTask(userID uint) *Task {
+	return &Task{
+		UserID:      userID,
+		Title:       t.Title,
+		Description: t.Description,
+		Type:        t.Type,
+		Queue:       t.Queue,
+		Payload:     t.Payload,
+		Priority:    t.Priority,
+		MaxAttempts: t.MaxAttempts,
+		Timeout:     t.Timeout,
+	}
+}
+
+// Schedule submits a task from its template whenever its cron expression
+// fires in its timezone. A paused schedule has no next run.
+type Schedule struct {
+	ID             uint         `json:"id" gorm:"primaryKey"`
+	UserID         uint         `json:"user_id" gorm:"index"`
+	Name           string       `json:"name" gorm:"size:255"`
+	CronExpression string       `json:"cron_expression" gorm:"size:100"`
+	Timezone       string       `json:"timezone" gorm:"size:64"`
+	Task           TaskTemplate `json:"task" gorm:"embedded;embeddedPrefix:task_"`
+	Paused         bool         `json:"paused"`
+	NextRunAt      *time.Time   `json:"next_run_at" gorm:"index"`
+	LastRunAt      *time.Time   `json:"last_run_at"`
+	LastTaskID     *uint        `json:"last_task_id,omitempty"`
+	LastError      string       `json:"last_error,omitempty" gorm:"type:text"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+type ScheduleRepository interface {
+	Create(ctx context.Context, schedule *Schedule) error
+	Update(ctx context.Context, schedule *Schedule) error
+	Delete(ctx context.Context, id, userID uint) error
+	FindByIDForUser(ctx context.Context, id, userID uint) (*Schedule, error)
+	ListByUser(ctx context.Context, userID uint) ([]*Schedule, error)
+	// FindDue returns active schedules whose next run is at or before now
+	FindDue(ctx context.Context, now time.Time, limit int) ([]*Schedule, error)
+	// Advance stores the schedule's new next and last run times if its next
+	// run is still due, or returns ErrScheduleNotDue if another scheduler or
+	// an update got there first
+	Advance(ctx context.Context, schedule *Schedule, due time.Time) error
+	// RecordRun stores the task created by the last run, or why it failed
+	RecordRun(ctx context.Context, id uint, taskID *uint, lastError string) error
+}
+
+type ScheduleService interface {
+	Create(ctx context.Context, schedule *Schedule) error
+	Get(ctx context.Context, userID, id uint) (*Schedule, error)
+	List(ctx context.Context, userID uint) ([]*Schedule, error)
+	Update(ctx context.Context, schedule *Schedule) error
+	Delete(ctx context.Context, userID, id uint) error
+	Pause(ctx context.Context, userID, id uint) (*Schedule, error)
+	Resume(ctx context.Context, userID, id uint) (*Schedule, error)
+}
@@ -15,16 +15,144 @@ type SwaggerUserResponse struct {
 	ID        uint   `json:"id" example:"1"`
 	Username  string `json:"username" example:"johndoe"`
 	Email     string `json:"email" example:"john@example.com"`
+	Role      string `json:"role" enums:"user,admin" example:"user"`
 	CreatedAt string `json:"created_at" example:"2025-05-31T15:04:05Z"`
 	UpdatedAt string `json:"updated_at" example:"2025-05-31T15:04:05Z"`
 }
 
 // SwaggerTask represents a task in the system for Swagger documentation
 type SwaggerTask struct {
-	ID          uint   `json:"id" example:"1"`
-	Title       string `json:"title" example:"Process Data"`
-	Description string `json:"description" example:"Process the uploaded data file"`
-	Status      string `json:"status" example:"pending"`
-	CreatedAt   string `json:"created_at" example:"2025-05-31T15:04:05Z"`
-	UpdatedAt   string `json:"updated_at" example:"2025-05-31T15:04:05Z"`
-} 
\ No newline at end of file
+	ID          uint                   `json:"id" example:"1"`
+	UserID      uint                   `json:"user_id" example:"1"`
+	Title       string                 `json:"title" example:"Process Data"`
+	Description string                 `json:"description" example:"Process the uploaded data file"`
+	Type        string                 `json:"type" example:"default"`
+	Queue       string                 `json:"queue" example:"default"`
+	Payload     map[string]interface{} `json:"payload,omitempty" swaggertype:"object"`
+	Priority    int                    `json:"priority" minimum:"0" maximum:"9" example:"0"`
+	RunAt       string                 `json:"run_at,omitempty" example:"2025-05-31T18:00:00Z"`
+	Status      string                 `json:"status" example:"pending"`
+	Attempts    int                    `json:"attempts" example:"1"`
+	MaxAttempts int                    `json:"max_attempts" example:"3"`
+	// Seconds an attempt may run; zero uses the server default
+	Timeout   int    `json:"timeout,omitempty" example:"300"`
+	LastError string `json:"last_error,omitempty" example:"connection refused"`
+	// Percent of the current attempt done, as reported by its handler
+	Progress        int    `json:"progress" minimum:"0" maximum:"100" example:"40"`
+	ProgressMessage string `json:"progress_message,omitempty" example:"Imported 400 of 1000 rows"`
+	WorkflowID      uint   `json:"workflow_id,omitempty" example:"1"`
+	// Tasks that must complete before this one starts; only listed on
+	// submission and in workflows
+	DependsOn        []uint `json:"depends_on,omitempty" example:"1,2"`
+	DependencyPolicy string `json:"dependency_policy,omitempty" enums:"cancel,fail,ignore" example:"cancel"`
+	CreatedAt        string `json:"created_at" example:"2025-05-31T15:04:05Z"`
+	UpdatedAt        string `json:"updated_at" example:"2025-05-31T15:04:05Z"`
+	// Only set in task listings, for tasks waiting to run: their position
+	// among the user's own ready tasks in their queue
+	QueuePosition int `json:"queue_position,omitempty" example:"3"`
+}
+
+// SwaggerTaskPage represents a page of tasks for Swagger documentation
+type SwaggerTaskPage struct {
+	Data       []SwaggerTask `json:"data"`
+	NextCursor string        `json:"next_cursor" example:"eyJ2IjoiMjAyNS0wNS0zMVQxNTowNDowNVoiLCJpZCI6NDJ9"`
+	Total      int64         `json:"total" example:"42"`
+}
+
+// SwaggerTaskResult represents a task result for Swagger documentation
+type SwaggerTaskResult struct {
+	TaskID     uint                   `json:"task_id" example:"1"`
+	Data       map[string]interface{} `json:"data" swaggertype:"object"`
+	OutputSize int64                  `json:"output_size,omitempty" example:"2048"`
+	ExpiresAt  string                 `json:"expires_at" example:"2025-06-07T15:04:05Z"`
+	CreatedAt  string                 `json:"created_at" example:"2025-05-31T15:04:05Z"`
+}
+
+// SwaggerTaskEvent represents the data of a task event for Swagger documentation
+type SwaggerTaskEvent struct {
+	ID        uint   `json:"id" example:"42"`
+	TaskID    uint   `json:"task_id" example:"1"`
+	UserID    uint   `json:"user_id" example:"1"`
+	Type      string `json:"type" enums:"status,progress" example:"status"`
+	Status    string `json:"status" example:"completed"`
+	Message   string `json:"message,omitempty" example:"connection refused"`
+	Progress  int    `json:"progress,omitempty" example:"40"`
+	CreatedAt string `json:"created_at" example:"2025-05-31T15:04:05Z"`
+}
+
+// SwaggerWebhook represents a webhook for Swagger documentation
+type SwaggerWebhook struct {
+	ID        uint     `json:"id" example:"1"`
+	UserID    uint     `json:"user_id" example:"1"`
+	URL       string   `json:"url" example:"https://example.com/hooks/tasks"`
+	Events    []string `json:"events" example:"task.completed,task.failed"`
+	Active    bool     `json:"active" example:"true"`
+	CreatedAt string   `json:"created_at" example:"2025-05-31T15:04:05Z"`
+	UpdatedAt string   `json:"updated_at" example:"2025-05-31T15:04:05Z"`
+}
+
+// SwaggerWebhookWithSecret represents a newly created webhook, including its
+// signing secret, for Swagger documentation
+type SwaggerWebhookWithSecret struct {
+	SwaggerWebhook
+	Secret string `json:"secret" example:"3f7a9c1e5b2d8f4a6c0e9b7d5f3a1c8e"`
+}
+
+// SwaggerWebhookDelivery represents a webhook delivery for Swagger documentation
+type SwaggerWebhookDelivery struct {
+	ID            uint                   `json:"id" example:"7"`
+	WebhookID     uint                   `json:"webhook_id" example:"1"`
+	TaskID        uint                   `json:"task_id" example:"1"`
+	Event         string                 `json:"event" example:"task.completed"`
+	Payload       map[string]interface{} `json:"payload" swaggertype:"object"`
+	Status        string                 `json:"status" example:"succeeded"`
+	Attempts      int                    `json:"attempts" example:"1"`
+	NextAttemptAt string                 `json:"next_attempt_at" example:"2025-05-31T15:04:05Z"`
+	ResponseCode  int                    `json:"response_code,omitempty" example:"200"`
+	LastError     string                 `json:"last_error,omitempty" example:"webhook responded with status 500"`
+	CreatedAt     string                 `json:"created_at" example:"2025-05-31T15:04:05Z"`
+	UpdatedAt     string                 `json:"updated_at" example:"2025-05-31T15:04:05Z"`
+}
+
+// SwaggerSchedule represents a schedule for Swagger documentation
+type SwaggerSchedule struct {
+	ID             uint                `json:"id" example:"1"`
+	UserID         uint                `json:"user_id" example:"1"`
+	Name           string              `json:"name" example:"Nightly report"`
+	CronExpression string              `json:"cron_expression" example:"0 2 * * *"`
+	Timezone       string              `json:"timezone" example:"Europe/Berlin"`
+	Task           SwaggerTaskTemplate `json:"task"`
+	Paused         bool                `json:"paused" example:"false"`
+	NextRunAt      string              `json:"next_run_at" example:"2025-06-01T00:00:00Z"`
+	LastRunAt      string              `json:"last_run_at" example:"2025-05-31T00:00:00Z"`
+	LastTaskID     uint                `json:"last_task_id,omitempty" example:"42"`
+	LastError      string              `json:"last_error,omitempty" example:"invalid task: unknown task type \"report\""`
+	CreatedAt      string              `json:"created_at" example:"2025-05-31T15:04:05Z"`
+	UpdatedAt      string              `json:"updated_at" example:"2025-05-31T15:04:05Z"`
+}
+
+// SwaggerTaskTemplate represents the task a schedule submits for Swagger
+// documentation
+type SwaggerTaskTemplate struct {
+	Title       string                 `json:"title" example:"Nightly report"`
+	Description string                 `json:"description" example:"Build the daily usage report"`
+	Type        string                 `json:"type" example:"default"`
+	Queue       string                 `json:"queue,omitempty" example:"reports"`
+	Payload     map[string]interface{} `json:"payload,omitempty" swaggertype:"object"`
+	Priority    int                    `json:"priority" example:"0"`
+	MaxAttempts int                    `json:"max_attempts" example:"3"`
+	Timeout     int                    `json:"timeout,omitempty" example:"300"`
+}
+
+// SwaggerWorkflow represents a workflow and its tasks for Swagger
+// documentation
+type SwaggerWorkflow struct {
+	ID        uint           `json:"id" example:"1"`
+	UserID    uint           `json:"user_id" example:"1"`
+	Name      string         `json:"name" example:"Nightly import"`
+	Status    string         `json:"status" enums:"pending,running,completed,failed,cancelled" example:"running"`
+	Counts    map[string]int `json:"counts"`
+	Tasks     []SwaggerTask  `json:"tasks"`
+	CreatedAt string         `json:"created_at" example:"2025-05-31T15:04:05Z"`
+	UpdatedAt string         `json:"updated_at" example:"2025-05-31T15:04:05Z"`
+}
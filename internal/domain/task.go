@@ -1,34 +1,135 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // Task represents a task entity
 type Task struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	UserID      uint   `json:"user_id" gorm:"index"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Type        string `json:"type" gorm:"size:100;index"`
+	Queue       string `json:"queue" gorm:"size:100;index;default:'default'"`
+	Payload     JSON   `json:"payload,omitempty" gorm:"type:json"`
+	Priority    int    `json:"priority"`
+	// RunAt delays the task until the given time
+	RunAt       *time.Time `json:"run_at,omitempty" gorm:"index"`
+	Status      TaskStatus `json:"status" gorm:"size:50;index"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	// Timeout is how many seconds an attempt may run before it is stopped
+	// and the task times out; zero uses the configured default
+	Timeout   int    `json:"timeout,omitempty"`
+	LastError string `json:"last_error,omitempty" gorm:"type:text"`
+	// Progress is the percentage of the current attempt that is done, as
+	// last reported by its handler, with an optional short message
+	Progress        int    `json:"progress"`
+	ProgressMessage string `json:"progress_message,omitempty" gorm:"size:255"`
+	// WorkflowID is set on tasks submitted as part of a workflow
+	WorkflowID *uint `json:"workflow_id,omitempty" gorm:"index"`
+	// DependsOn lists the tasks that must finish before this one starts. It
+	// is given on submission and only filled in workflow views.
+	DependsOn        []uint           `json:"depends_on,omitempty" gorm:"-"`
+	DependencyPolicy DependencyPolicy `json:"dependency_policy,omitempty" gorm:"size:20"`
+	CreatedAt        time.Time        `json:"created_at" gorm:"index"`
+	UpdatedAt        time.Time        `json:"updated_at" gorm:"index"`
+	// QueuePosition is the 1-based position of a task that is waiting to
+	// run among its owner's ready tasks in its queue; it is only set in
+	// task listings
+	QueuePosition *int `json:"queue_position,omitempty" gorm:"-"`
+}
+
+// Task priorities; tasks with a higher priority are run first
+const (
+	MinTaskPriority = 0
+	MaxTaskPriority = 9
+)
+
+// IsFinished reports whether the task has reached a final status
+func (t *Task) IsFinished() bool {
+	return t.Status.IsFinal()
+}
+
+// Task listing defaults and limits
+const (
+	DefaultTaskPageSize = 20
+	MaxTaskPageSize     = 100
+)
+
+// TaskFilter selects, orders and pages a user's tasks
+type TaskFilter struct {
+	UserID        uint
+	Status        TaskStatus
+	Title         string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	SortBy        string // created_at or updated_at
+	SortOrder     string // asc or desc
+	Cursor        string
+	Limit         int
+}
+
+// TaskPage is a single page of tasks
+type TaskPage struct {
+	Tasks      []*Task `json:"data"`
+	NextCursor string  `json:"next_cursor"`
+	Total      int64   `json:"total"`
 }
 
 // TaskRepository defines the interface for task persistence
 type TaskRepository interface {
-	Create(task *Task) error
-	Update(task *Task) error
-	FindByID(id uint) (*Task, error)
-	FindAll() ([]*Task, error)
+	Create(ctx context.Context, task *Task) error
+	// Update saves the task's status and updated_at, and of its other
+	// fields only the given columns
+	Update(ctx context.Context, task *Task, columns ...string) error
+	FindByID(ctx context.Context, id uint) (*Task, error)
+	FindByIDForUser(ctx context.Context, id, userID uint) (*Task, error)
+	// UpdateProgress saves the progress of a task that is still processing
+	UpdateProgress(ctx context.Context, task *Task) error
+	List(ctx context.Context, filter TaskFilter) (*TaskPage, error)
+	FindByStatus(ctx context.Context, statuses ...TaskStatus) ([]*Task, error)
+	FindByIDsForUser(ctx context.Context, ids []uint, userID uint) ([]*Task, error)
+	// FindDependencies returns the tasks the task depends on
+	FindDependencies(ctx context.Context, taskID uint) ([]*Task, error)
+	// FindBlockedDependents returns the blocked tasks that depend on the task
+	FindBlockedDependents(ctx context.Context, taskID uint) ([]*Task, error)
 }
 
 // TaskProcessor defines the interface for task processing
 type TaskProcessor interface {
-	Process(task *Task) error
-	Shutdown()
+	// Admit returns ErrQueueFull if the tasks would not fit in their
+	// queues, so that new submissions are refused until they drain,
+	// ErrQueueDraining if one of their queues is draining, or
+	// ErrInvalidTask if one of them names an unknown queue. Blocked tasks
+	// do not count against the capacity of their queue.
+	Admit(ctx context.Context, tasks []*Task) error
+	Process(ctx context.Context, task *Task) error
+	Cancel(ctx context.Context, taskID uint) error
+	// Queues describes every configured queue
+	Queues(ctx context.Context) ([]*QueueStats, error)
+	// Pause, Resume and Drain change the state of a queue on every server
+	// and return its new state on this one, or ErrQueueNotFound
+	Pause(ctx context.Context, queue string) (QueueState, error)
+	Resume(ctx context.Context, queue string) (QueueState, error)
+	Drain(ctx context.Context, queue string) (QueueState, error)
+	// States returns the state of every configured queue on this server
+	States() map[string]QueueState
+	// Shutdown waits for running tasks to finish until ctx ends, then stops
+	// them and leaves them to be claimed again
+	Shutdown(ctx context.Context) error
 }
 
 // TaskService defines the interface for task business logic
 type TaskService interface {
-	SubmitTask(task *Task) error
-	GetTaskStatus(id uint) (*Task, error)
-	GetAllTasks() ([]*Task, error)
-} 
\ No newline at end of file
+	SubmitTask(ctx context.Context, task *Task) error
+	GetTaskStatus(ctx context.Context, userID, id uint) (*Task, error)
+	GetTaskResult(ctx context.Context, userID, id uint) (*TaskResult, error)
+	GetAllTasks(ctx context.Context, filter TaskFilter) (*TaskPage, error)
+	CancelTask(ctx context.Context, userID, id uint) (*Task, error)
+	RequeueTask(ctx context.Context, userID, id uint) (*Task, error)
+}
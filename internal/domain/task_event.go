@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Task event types
+const (
+	TaskEventStatus   = "status"
+	TaskEventProgress = "progress"
+)
+
+// TaskEvent records a change to a task that clients can follow. IDs grow
+// monotonically, so a client can resume a stream after the last ID it saw.
+// Progress is only set on progress events.
+type TaskEvent struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	TaskID    uint       `json:"task_id" gorm:"index"`
+	UserID    uint       `json:"user_id" gorm:"index"`
+	Type      string     `json:"type" gorm:"size:50"`
+	Status    TaskStatus `json:"status" gorm:"size:50"`
+	Message   string     `json:"message,omitempty" gorm:"type:text"`
+	Progress  *int       `json:"progress,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"index"`
+}
+
+// TaskEventFilter selects a user's events that follow AfterID, optionally
+// for a single task
+type TaskEventFilter struct {
+	UserID  uint
+	TaskID  uint
+	AfterID uint
+	Limit   int
+}
+
+// TaskEventRepository stores task events until they expire
+type TaskEventRepository interface {
+	Append(ctx context.Context, event *TaskEvent) error
+	List(ctx context.Context, filter TaskEventFilter) ([]*TaskEvent, error)
+	LastID(ctx context.Context, userID uint) (uint, error)
+	DeleteBefore(ctx context.Context, t time.Time) (int64, error)
+}
+
+// TaskEventPublisher records task status changes and the progress of
+// running tasks as events
+type TaskEventPublisher interface {
+	Publish(task *Task)
+	PublishProgress(task *Task)
+}
+
+// TaskEventBroker publishes task events and lets clients follow them
+type TaskEventBroker interface {
+	TaskEventPublisher
+	List(ctx context.Context, filter TaskEventFilter) ([]*TaskEvent, error)
+	// LastID returns the ID of the user's most recent event, or 0
+	LastID(ctx context.Context, userID uint) (uint, error)
+	// Subscribe returns a channel that receives a signal whenever new events
+	// may be available for the user, and a function that ends the
+	// subscription
+	Subscribe(userID uint) (<-chan struct{}, func())
+	// Done is closed when the broker shuts down and streams should end
+	Done() <-chan struct{}
+	Close()
+}
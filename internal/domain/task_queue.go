@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultQueue is the queue of tasks submitted without one
+const DefaultQueue = "default"
+
+// QueuedTask represents a task waiting in the durable processing queue
+type QueuedTask struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	TaskID         uint       `json:"task_id" gorm:"uniqueIndex"`
+	Queue          string     `json:"queue" gorm:"size:100;index;default:'default'"`
+	UserID         uint       `json:"user_id" gorm:"index"`
+	Priority       int        `json:"priority"`
+	LeaseOwner     string     `json:"lease_owner"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at" gorm:"index"`
+	AvailableAt    time.Time  `json:"available_at" gorm:"index"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName returns the table backing the task queue
+func (QueuedTask) TableName() string {
+	return "task_queue"
+}
+
+// QueueBacklog describes the entries that are ready to be claimed
+// within the per-user limit
+type QueueBacklog struct {
+	Ready int64
+	// OldestWait is how long the longest waiting ready entry has been
+	// claimable
+	OldestWait time.Duration
+}
+
+// UserShare describes the entries one user has in a named queue
+type UserShare struct {
+	UserID   uint
+	Ready    int64
+	InFlight int64
+	// OldestReady is when the longest waiting ready entry became claimable
+	OldestReady *time.Time
+}
+
+// QueueCounts tells how many entries of a named queue are waiting to be
+// claimed, including delayed ones, and how many are held by workers
+type QueueCounts struct {
+	Depth    int64
+	InFlight int64
+}
+
+// QueueState tells whether servers start the tasks of a queue. A paused
+// queue accepts and holds new tasks, while a draining queue refuses them and
+// becomes drained on a server once the tasks it was running there have
+// finished.
+type QueueState string
+
+const (
+	QueueStateRunning  QueueState = "running"
+	QueueStatePaused   QueueState = "paused"
+	QueueStateDraining QueueState = "draining"
+	QueueStateDrained  QueueState = "drained"
+)
+
+// AcceptsTasks reports whether new tasks may be submitted to the queue
+func (s QueueState) AcceptsTasks() bool {
+	return s == QueueStateRunning || s == QueueStatePaused
+}
+
+// QueueStateRecord stores the state an administrator put a named queue in,
+// so that every server follows it and it outlasts restarts. Queues without
+// a record are running.
+type QueueStateRecord struct {
+	Queue     string     `gorm:"primaryKey;size:100"`
+	State     QueueState `gorm:"size:20"`
+	UpdatedAt time.Time
+}
+
+// TableName returns the table backing queue states
+func (QueueStateRecord) TableName() string {
+	return "queue_states"
+}
+
+type QueueStateRepository interface {
+	// Set stores the state of a queue; it is running, paused or draining
+	Set(ctx context.Context, queue string, state QueueState) error
+	// All returns the stored state of every queue that has one
+	All(ctx context.Context) (map[string]QueueState, error)
+}
+
+// QueueStats describes a named queue across all servers and the worker
+// pool that runs it on this one. UserConcurrency is the most tasks of one
+// user the queue runs at once across all servers.
+type QueueStats struct {
+	Name            string     `json:"name"`
+	State           QueueState `json:"state"`
+	Depth           int64      `json:"depth"`
+	InFlight        int64      `json:"in_flight"`
+	Workers         int        `json:"workers"`
+	Busy            int        `json:"busy"`
+	MinWorkers      int        `json:"min_workers"`
+	Concurrency     int        `json:"concurrency"`
+	Capacity        int        `json:"capacity"`
+	RateLimit       float64    `json:"rate_limit,omitempty"`
+	UserConcurrency int        `json:"user_concurrency,omitempty"`
+}
+
+// TaskQueue defines the interface for the durable task queue.
+// Workers claim entries with a lease; an entry whose lease expires
+// becomes claimable again, so work survives a crash or restart.
+// Entries are not claimable before their AvailableAt time. Each entry
+// belongs to the named queue and the owner of its task; workers only claim
+// from their own queue, taking turns between the users in it. Among one
+// user's entries higher priorities are claimed first, but an entry's
+// priority rises the longer it waits so low priority work is never starved.
+type TaskQueue interface {
+	// Enqueue adds the task with its priority, to be claimable from its
+	// RunAt time or right away
+	Enqueue(ctx context.Context, task *Task) error
+	// Claim leases the user's best ready entry in the queue
+	Claim(ctx context.Context, queue string, userID uint, owner string, lease time.Duration) (*QueuedTask, error)
+	ExtendLease(ctx context.Context, entry *QueuedTask, lease time.Duration) error
+	Complete(ctx context.Context, entry *QueuedTask) error
+	Retry(ctx context.Context, entry *QueuedTask, availableAt time.Time) error
+	FindByTaskID(ctx context.Context, taskID uint) (*QueuedTask, error)
+	Remove(ctx context.Context, taskID uint) error
+	// Depth returns how many entries of the queue are ready to be claimed.
+	// Entries waiting for their run time or a retry are not counted.
+	Depth(ctx context.Context, queue string) (int64, error)
+	// Shares returns the ready and in-flight entries of each user with
+	// entries in the queue
+	Shares(ctx context.Context, queue string) ([]UserShare, error)
+	// Counts returns the counts of every queue that has entries
+	Counts(ctx context.Context) (map[string]QueueCounts, error)
+	// Positions returns the 1-based claim order of the given tasks among the
+	// ready entries their owner has in their queue. Tasks that are leased,
+	// waiting for a retry or not queued are left out.
+	Positions(ctx context.Context, taskIDs []uint) (map[uint]int, error)
+}
+
+// IsLeased reports whether the entry is currently held by a worker
+func (q *QueuedTask) IsLeased(now time.Time) bool {
+	return q.LeaseExpiresAt != nil && q.LeaseExpiresAt.After(now)
+}
+
+// LeaseExpired reports whether the entry was claimed by a worker that let
+// its lease run out, for example because its server stopped
+func (q *QueuedTask) LeaseExpired(now time.Time) bool {
+	return q.LeaseExpiresAt != nil && !q.LeaseExpiresAt.After(now)
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// TaskResult is the structured output of a completed task
+type TaskResult struct {
+	ID         uint      `json:"-" gorm:"primaryKey"`
+	TaskID     uint      `json:"task_id" gorm:"uniqueIndex"`
+	Data       JSON      `json:"data" gorm:"type:json"`
+	OutputSize *int64    `json:"output_size,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TaskResultRepository stores task results until they expire
+type TaskResultRepository interface {
+	Save(ctx context.Context, result *TaskResult) error
+	FindByTaskID(ctx context.Context, taskID uint) (*TaskResult, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
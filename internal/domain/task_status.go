@@ -0,0 +1,80 @@
+package domain
+
+// TaskStatus is the lifecycle state of a task
+type TaskStatus string
+
+const (
+	TaskStatusBlocked    TaskStatus = "blocked"
+	TaskStatusPending    TaskStatus = "pending"
+	TaskStatusProcessing TaskStatus = "processing"
+	TaskStatusCompleted  TaskStatus = "completed"
+	TaskStatusFailed     TaskStatus = "failed"
+	TaskStatusCancelled  TaskStatus = "cancelled"
+	TaskStatusDead       TaskStatus = "dead"
+	TaskStatusTimedOut   TaskStatus = "timed_out"
+)
+
+// taskStatusTransitions lists the statuses each status may move to.
+// A blocked task waits for its dependencies and then becomes pending, or is
+// cancelled or failed along with a dependency. A failed attempt moves a
+// processing task back to pending for a retry, or to dead once it runs out
+// of attempts; dead tasks can only be requeued manually. A task whose
+// handler runs past its timeout is not retried.
+var taskStatusTransitions = map[TaskStatus][]TaskStatus{
+	TaskStatusBlocked: {
+		TaskStatusPending,
+		TaskStatusFailed,
+		TaskStatusCancelled,
+	},
+	TaskStatusPending: {
+		TaskStatusProcessing,
+		TaskStatusFailed,
+		TaskStatusCancelled,
+	},
+	TaskStatusProcessing: {
+		TaskStatusPending,
+		TaskStatusCompleted,
+		TaskStatusFailed,
+		TaskStatusCancelled,
+		TaskStatusDead,
+		TaskStatusTimedOut,
+	},
+	TaskStatusCompleted: {},
+	TaskStatusFailed:    {},
+	TaskStatusCancelled: {},
+	TaskStatusTimedOut:  {},
+	TaskStatusDead: {
+		TaskStatusPending,
+	},
+}
+
+// IsValid reports whether the status is a known task status
+func (s TaskStatus) IsValid() bool {
+	_, ok := taskStatusTransitions[s]
+	return ok
+}
+
+// IsFinal reports whether the task will not run again without manual
+// intervention
+func (s TaskStatus) IsFinal() bool {
+	switch s {
+	case TaskStatusCompleted, TaskStatusFailed, TaskStatusCancelled, TaskStatusDead, TaskStatusTimedOut:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo reports whether a task may move from s to next. Staying
+// in the same non-final status is allowed so other fields can be updated,
+// and lets a worker resume a task whose previous worker died.
+func (s TaskStatus) CanTransitionTo(next TaskStatus) bool {
+	if s == next {
+		return s.IsValid() && !s.IsFinal()
+	}
+	for _, allowed := range taskStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskStatusTransitions(t *testing.T) {
+	tests := []struct {
+		from    TaskStatus
+		to      TaskStatus
+		allowed bool
+	}{
+		{TaskStatusPending, TaskStatusProcessing, true},
+		{TaskStatusPending, TaskStatusCancelled, true},
+		{TaskStatusPending, TaskStatusCompleted, false},
+		{TaskStatusProcessing, TaskStatusProcessing, true},
+		{TaskStatusProcessing, TaskStatusCompleted, true},
+		{TaskStatusProcessing, TaskStatusFailed, true},
+		{TaskStatusProcessing, TaskStatusCancelled, true},
+		{TaskStatusProcessing, TaskStatusPending, true},
+		{TaskStatusProcessing, TaskStatusDead, true},
+		{TaskStatusPending, TaskStatusDead, false},
+		{TaskStatusDead, TaskStatusPending, true},
+		{TaskStatusDead, TaskStatusProcessing, false},
+		{TaskStatusCompleted, TaskStatusCompleted, false},
+		{TaskStatusCompleted, TaskStatusFailed, false},
+		{TaskStatusCancelled, TaskStatusProcessing, false},
+		{TaskStatusProcessing, TaskStatusTimedOut, true},
+		{TaskStatusPending, TaskStatusTimedOut, false},
+		{TaskStatusTimedOut, TaskStatusPending, false},
+		{TaskStatusBlocked, TaskStatusPending, true},
+		{TaskStatusBlocked, TaskStatusCancelled, true},
+		{TaskStatusBlocked, TaskStatusProcessing, false},
+		{TaskStatusPending, TaskStatusBlocked, false},
+		{TaskStatus("unknown"), TaskStatusProcessing, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, tt.from.CanTransitionTo(tt.to), "%s -> %s", tt.from, tt.to)
+	}
+}
+
+func TestTaskStatusIsFinal(t *testing.T) {
+	assert.False(t, TaskStatusBlocked.IsFinal())
+	assert.False(t, TaskStatusPending.IsFinal())
+	assert.False(t, TaskStatusProcessing.IsFinal())
+	assert.True(t, TaskStatusCompleted.IsFinal())
+	assert.True(t, TaskStatusFailed.IsFinal())
+	assert.True(t, TaskStatusCancelled.IsFinal())
+	assert.True(t, TaskStatusDead.IsFinal())
+	assert.True(t, TaskStatusTimedOut.IsFinal())
+	assert.False(t, TaskStatus("unknown").IsFinal())
+}
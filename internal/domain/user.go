@@ -4,12 +4,19 @@ import (
 	"time"
 )
 
+// User roles
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+)
+
 // User represents a user entity
 type User struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
 	Username  string    `json:"username" gorm:"unique"`
 	Password  string    `json:"-"`
 	Email     string    `json:"email" gorm:"unique"`
+	Role      string    `json:"role" gorm:"size:20;default:'user'"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -31,11 +38,17 @@ type UserService interface {
 	Delete(id uint) error
 }
 
+// IsAdmin reports whether the user may use the admin endpoints
+func (u *User) IsAdmin() bool {
+	return u.Role == UserRoleAdmin
+}
+
 // UserResponse is the DTO for user data
 type UserResponse struct {
 	ID        uint      `json:"id"`
 	Email     string    `json:"email"`
 	Username  string    `json:"username"`
+	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -45,6 +58,7 @@ func (u *User) ToResponse() *UserResponse {
 		ID:        u.ID,
 		Email:     u.Email,
 		Username:  u.Username,
+		Role:      u.Role,
 		CreatedAt: u.CreatedAt,
 	}
 } 
\ No newline at end of file
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// WebhookEventPrefix prefixes the task status in webhook event names, such
+// as task.completed
+const WebhookEventPrefix = "task."
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookEventName returns the webhook event name for a task status
+func WebhookEventName(status TaskStatus) string {
+	return WebhookEventPrefix + string(status)
+}
+
+// Webhook is an endpoint that receives the owner's task events
+type Webhook struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	UserID uint   `json:"user_id" gorm:"index"`
+	URL    string `json:"url" gorm:"size:2048"`
+	// Secret signs deliveries; it is only revealed when the webhook is created
+	Secret    string     `json:"-" gorm:"size:255"`
+	Events    StringList `json:"events" gorm:"type:json"`
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Accepts reports whether the webhook subscribes to the event. An empty
+// event filter subscribes to every event.
+func (w *Webhook) Accepts(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event sent, or still to be sent, to a webhook
+type WebhookDelivery struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	WebhookID     uint      `json:"webhook_id" gorm:"index"`
+	TaskID        uint      `json:"task_id"`
+	Event         string    `json:"event" gorm:"size:100"`
+	Payload       JSON      `json:"payload" gorm:"type:json"`
+	Status        string    `json:"status" gorm:"size:20;index:idx_webhook_deliveries_due,priority:1"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at" gorm:"index:idx_webhook_deliveries_due,priority:2"`
+	ResponseCode  int       `json:"response_code,omitempty"`
+	LastError     string    `json:"last_error,omitempty" gorm:"type:text"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *Webhook) error
+	Update(ctx context.Context, webhook *Webhook) error
+	Delete(ctx context.Context, id, userID uint) error
+	FindByIDForUser(ctx context.Context, id, userID uint) (*Webhook, error)
+	FindByID(ctx context.Context, id uint) (*Webhook, error)
+	ListByUser(ctx context.Context, userID uint) ([]*Webhook, error)
+	FindActiveByUser(ctx context.Context, userID uint) ([]*Webhook, error)
+}
+
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, deliveries []*WebhookDelivery) error
+	// Claim leases the oldest due pending delivery by pushing its next
+	// attempt past the lease, or returns ErrQueueEmpty
+	Claim(ctx context.Context, lease time.Duration) (*WebhookDelivery, error)
+	Update(ctx context.Context, delivery *WebhookDelivery) error
+	ListByWebhook(ctx context.Context, webhookID uint, limit int) ([]*WebhookDelivery, error)
+}
+
+type WebhookService interface {
+	Create(ctx context.Context, webhook *Webhook) error
+	Get(ctx context.Context, userID, id uint) (*Webhook, error)
+	List(ctx context.Context, userID uint) ([]*Webhook, error)
+	Update(ctx context.Context, webhook *Webhook) error
+	Delete(ctx context.Context, userID, id uint) error
+	ListDeliveries(ctx context.Context, userID, id uint, limit int) ([]*WebhookDelivery, error)
+}
+
+// StringList is a list of strings stored as a JSON array
+type StringList []string
+
+// Value implements driver.Valuer
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		l = StringList{}
+	}
+	data, err := json.Marshal([]string(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (l *StringList) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*[]string)(l))
+	case string:
+		return json.Unmarshal([]byte(v), (*[]string)(l))
+	default:
+		return fmt.Errorf("cannot scan %T into StringList", value)
+	}
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// DependencyPolicy decides what happens to a task when one of its
+// dependencies finishes without completing
+type DependencyPolicy string
+
+const (
+	// DependencyPolicyCancel cancels the task; it is the default
+	DependencyPolicyCancel DependencyPolicy = "cancel"
+	// DependencyPolicyFail fails the task
+	DependencyPolicyFail DependencyPolicy = "fail"
+	// DependencyPolicyIgnore runs the task once every dependency has
+	// finished, however it finished
+	DependencyPolicyIgnore DependencyPolicy = "ignore"
+)
+
+// IsValid reports whether the policy is known; the empty policy means cancel
+func (p DependencyPolicy) IsValid() bool {
+	switch p {
+	case "", DependencyPolicyCancel, DependencyPolicyFail, DependencyPolicyIgnore:
+		return true
+	}
+	return false
+}
+
+// TaskDependency records that a task may only start once another has
+// finished
+type TaskDependency struct {
+	TaskID      uint `gorm:"primaryKey"`
+	DependsOnID uint `gorm:"primaryKey;index"`
+}
+
+// WorkflowStatus is the aggregate status of a workflow's tasks
+type WorkflowStatus string
+
+const (
+	WorkflowStatusPending   WorkflowStatus = "pending"
+	WorkflowStatusRunning   WorkflowStatus = "running"
+	WorkflowStatusCompleted WorkflowStatus = "completed"
+	WorkflowStatusFailed    WorkflowStatus = "failed"
+	WorkflowStatusCancelled WorkflowStatus = "cancelled"
+)
+
+// Workflow is a group of tasks submitted together whose dependencies form a
+// directed acyclic graph
+type Workflow struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"index"`
+	Name      string    `json:"name" gorm:"size:255"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Filled when a workflow is read
+	Status WorkflowStatus     `json:"status" gorm:"-"`
+	Counts map[TaskStatus]int `json:"counts" gorm:"-"`
+	Tasks  []*Task            `json:"tasks" gorm:"-"`
+}
+
+// Summarize sets the workflow's status and counts from its tasks. A workflow
+// is finished once all of its tasks are; it failed if any task failed or
+// died or timed out, and was cancelled if any other task was cancelled.
+func (w *Workflow) Summarize() {
+	w.Counts = make(map[TaskStatus]int)
+	for _, task := range w.Tasks {
+		w.Counts[task.Status]++
+	}
+
+	finished := 0
+	for status, count := range w.Counts {
+		if status.IsFinal() {
+			finished += count
+		}
+	}
+
+	switch {
+	case finished == len(w.Tasks) && w.Counts[TaskStatusFailed]+w.Counts[TaskStatusDead]+w.Counts[TaskStatusTimedOut] > 0:
+		w.Status = WorkflowStatusFailed
+	case finished == len(w.Tasks) && w.Counts[TaskStatusCancelled] > 0:
+		w.Status = WorkflowStatusCancelled
+	case finished == len(w.Tasks):
+		w.Status = WorkflowStatusCompleted
+	case finished > 0 || w.Counts[TaskStatusProcessing] > 0:
+		w.Status = WorkflowStatusRunning
+	default:
+		w.Status = WorkflowStatusPending
+	}
+}
+
+// WorkflowTask is a task of a workflow submission. Tasks of the submission
+// refer to each other by key.
+type WorkflowTask struct {
+	Key       string
+	Task      *Task
+	DependsOn []string
+}
+
+type WorkflowRepository interface {
+	// Create stores the workflow and its tasks in one transaction. Tasks are
+	// given in dependency order and parents[i] lists the indexes of the tasks
+	// task i depends on.
+	Create(ctx context.Context, workflow *Workflow, tasks []*Task, parents [][]int) error
+	// FindByIDForUser returns the workflow with its tasks and their
+	// dependencies
+	FindByIDForUser(ctx context.Context, id, userID uint) (*Workflow, error)
+}
+
+type WorkflowService interface {
+	Submit(ctx context.Context, workflow *Workflow, tasks []WorkflowTask) error
+	Get(ctx context.Context, userID, id uint) (*Workflow, error)
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkflow_Summarize(t *testing.T) {
+	tests := []struct {
+		statuses []TaskStatus
+		want     WorkflowStatus
+	}{
+		{[]TaskStatus{TaskStatusPending, TaskStatusBlocked}, WorkflowStatusPending},
+		{[]TaskStatus{TaskStatusProcessing, TaskStatusBlocked}, WorkflowStatusRunning},
+		{[]TaskStatus{TaskStatusCompleted, TaskStatusPending}, WorkflowStatusRunning},
+		{[]TaskStatus{TaskStatusCompleted, TaskStatusCompleted}, WorkflowStatusCompleted},
+		{[]TaskStatus{TaskStatusDead, TaskStatusCancelled}, WorkflowStatusFailed},
+		{[]TaskStatus{TaskStatusCompleted, TaskStatusCancelled}, WorkflowStatusCancelled},
+	}
+	for _, tt := range tests {
+		workflow := &Workflow{}
+		for _, status := range tt.statuses {
+			workflow.Tasks = append(workflow.Tasks, &Task{Status: status})
+		}
+		workflow.Summarize()
+		assert.Equal(t, tt.want, workflow.Status, tt.statuses)
+	}
+}
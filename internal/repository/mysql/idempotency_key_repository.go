@@ -0,0 +1,58 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"golangwithgin/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type idempotencyKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyKeyRepository creates a new MySQL-backed idempotency key store
+func NewIdempotencyKeyRepository(db *gorm.DB) domain.IdempotencyKeyRepository {
+	return &idempotencyKeyRepository{db: db}
+}
+
+// Create inserts the key, relying on the unique (user_id, idempotency_key)
+// index so that only one of several concurrent requests reserves it
+func (r *idempotencyKeyRepository) Create(ctx context.Context, key *domain.IdempotencyKey) error {
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrIdempotencyKeyExists
+	}
+	return nil
+}
+
+func (r *idempotencyKeyRepository) Find(ctx context.Context, userID uint, key string) (*domain.IdempotencyKey, error) {
+	var record domain.IdempotencyKey
+	err := r.db.WithContext(ctx).Where("user_id = ? AND idempotency_key = ?", userID, key).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrIdempotencyKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *idempotencyKeyRepository) Update(ctx context.Context, key *domain.IdempotencyKey) error {
+	return r.db.WithContext(ctx).Save(key).Error
+}
+
+func (r *idempotencyKeyRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&domain.IdempotencyKey{}, id).Error
+}
+
+func (r *idempotencyKeyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&domain.IdempotencyKey{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,40 @@
+package mysql
+
+import (
+	"context"
+	"golangwithgin/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type queueStateRepository struct {
+	db *gorm.DB
+}
+
+// NewQueueStateRepository creates a new MySQL-backed store of queue states
+func NewQueueStateRepository(db *gorm.DB) domain.QueueStateRepository {
+	return &queueStateRepository{db: db}
+}
+
+func (r *queueStateRepository) Set(ctx context.Context, queue string, state domain.QueueState) error {
+	record := domain.QueueStateRecord{Queue: queue, State: state, UpdatedAt: time.Now()}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "queue"}},
+		DoUpdates: clause.AssignmentColumns([]string{"state", "updated_at"}),
+	}).Create(&record).Error
+}
+
+func (r *queueStateRepository) All(ctx context.Context) (map[string]domain.QueueState, error) {
+	var records []domain.QueueStateRecord
+	if err := r.db.WithContext(ctx).Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]domain.QueueState, len(records))
+	for _, record := range records {
+		states[record.Queue] = record.State
+	}
+	return states, nil
+}
@@ -0,0 +1,104 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"golangwithgin/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type scheduleRepository struct {
+	db *gorm.DB
+}
+
+// NewScheduleRepository creates a new schedule repository
+func NewScheduleRepository(db *gorm.DB) domain.ScheduleRepository {
+	return &scheduleRepository{db: db}
+}
+
+func (r *scheduleRepository) Create(ctx context.Context, schedule *domain.Schedule) error {
+	return r.db.WithContext(ctx).Create(schedule).Error
+}
+
+// Update saves all fields of a schedule, scoped to the schedule's owner
+func (r *scheduleRepository) Update(ctx context.Context, schedule *domain.Schedule) error {
+	res := r.db.WithContext(ctx).Model(schedule).
+		Where("user_id = ?", schedule.UserID).
+		Select("*").Omit("created_at").
+		Updates(schedule)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrScheduleNotFound
+	}
+	return nil
+}
+
+func (r *scheduleRepository) Delete(ctx context.Context, id, userID uint) error {
+	res := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&domain.Schedule{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrScheduleNotFound
+	}
+	return nil
+}
+
+func (r *scheduleRepository) FindByIDForUser(ctx context.Context, id, userID uint) (*domain.Schedule, error) {
+	var schedule domain.Schedule
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&schedule, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrScheduleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *scheduleRepository) ListByUser(ctx context.Context, userID uint) ([]*domain.Schedule, error) {
+	var schedules []*domain.Schedule
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&schedules).Error
+	return schedules, err
+}
+
+func (r *scheduleRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]*domain.Schedule, error) {
+	var schedules []*domain.Schedule
+	err := r.db.WithContext(ctx).Where("paused = ? AND next_run_at <= ?", false, now).
+		Order("next_run_at").
+		Limit(limit).
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// Advance only succeeds for the caller that still sees the due run, so each
+// run is submitted at most once across restarts and server instances
+func (r *scheduleRepository) Advance(ctx context.Context, schedule *domain.Schedule, due time.Time) error {
+	res := r.db.WithContext(ctx).Model(&domain.Schedule{}).
+		Where("id = ? AND paused = ? AND next_run_at = ?", schedule.ID, false, due).
+		Updates(map[string]interface{}{
+			"next_run_at": schedule.NextRunAt,
+			"last_run_at": schedule.LastRunAt,
+			"updated_at":  time.Now(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrScheduleNotDue
+	}
+	return nil
+}
+
+func (r *scheduleRepository) RecordRun(ctx context.Context, id uint, taskID *uint, lastError string) error {
+	return r.db.WithContext(ctx).Model(&domain.Schedule{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_task_id": taskID,
+			"last_error":   lastError,
+		}).Error
+}
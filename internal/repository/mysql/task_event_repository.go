@@ -0,0 +1,54 @@
+package mysql
+
+import (
+	"context"
+	"golangwithgin/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type taskEventRepository struct {
+	db *gorm.DB
+}
+
+// NewTaskEventRepository creates a new MySQL-backed task event store
+func NewTaskEventRepository(db *gorm.DB) domain.TaskEventRepository {
+	return &taskEventRepository{db: db}
+}
+
+func (r *taskEventRepository) Append(ctx context.Context, event *domain.TaskEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// List returns the user's events after filter.AfterID in the order they
+// were recorded
+func (r *taskEventRepository) List(ctx context.Context, filter domain.TaskEventFilter) ([]*domain.TaskEvent, error) {
+	query := r.db.WithContext(ctx).Where("user_id = ? AND id > ?", filter.UserID, filter.AfterID)
+	if filter.TaskID != 0 {
+		query = query.Where("task_id = ?", filter.TaskID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var events []*domain.TaskEvent
+	if err := query.Order("id").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *taskEventRepository) LastID(ctx context.Context, userID uint) (uint, error) {
+	var id uint
+	err := r.db.WithContext(ctx).Model(&domain.TaskEvent{}).
+		Where("user_id = ?", userID).
+		Select("COALESCE(MAX(id), 0)").
+		Scan(&id).Error
+	return id, err
+}
+
+func (r *taskEventRepository) DeleteBefore(ctx context.Context, t time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("created_at < ?", t).Delete(&domain.TaskEvent{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,250 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"golangwithgin/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultPriorityAging is how long an entry waits before its priority is
+// raised by one when no aging interval is configured
+const DefaultPriorityAging = 30 * time.Second
+
+// claimCandidates is how many of the best entries a claim considers before
+// locking one
+const claimCandidates = 10
+
+type taskQueueRepository struct {
+	db           *gorm.DB
+	agingSeconds int64
+}
+
+// NewTaskQueueRepository creates a new MySQL-backed task queue. Every aging
+// interval an entry waits raises its priority by one.
+func NewTaskQueueRepository(db *gorm.DB, aging time.Duration) domain.TaskQueue {
+	if aging < time.Second {
+		aging = DefaultPriorityAging
+	}
+	return &taskQueueRepository{db: db, agingSeconds: int64(aging / time.Second)}
+}
+
+func (r *taskQueueRepository) Enqueue(ctx context.Context, task *domain.Task) error {
+	entry := &domain.QueuedTask{TaskID: task.ID, Queue: task.Queue, UserID: task.UserID, Priority: task.Priority, AvailableAt: time.Now()}
+	if entry.Queue == "" {
+		entry.Queue = domain.DefaultQueue
+	}
+	if task.RunAt != nil && task.RunAt.After(entry.AvailableAt) {
+		entry.AvailableAt = *task.RunAt
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(entry).Error
+}
+
+// claimOrder orders entries by their aged priority, oldest first among equals
+func (r *taskQueueRepository) claimOrder(now time.Time) clause.OrderBy {
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:                "priority + FLOOR(TIMESTAMPDIFF(SECOND, available_at, ?) / ?) DESC, id",
+		Vars:               []interface{}{now, r.agingSeconds},
+		WithoutParentheses: true,
+	}}
+}
+
+// ready restricts a query to entries that may be claimed at now
+func ready(tx *gorm.DB, now time.Time) *gorm.DB {
+	return tx.Where("lease_expires_at IS NULL OR lease_expires_at < ?", now).
+		Where("available_at <= ?", now)
+}
+
+// Claim leases the user's available entry in the queue with the highest
+// aged priority that is not currently leased. Rows locked by other workers
+// are skipped so concurrent claims do not block each other.
+func (r *taskQueueRepository) Claim(ctx context.Context, queue string, userID uint, owner string, lease time.Duration) (*domain.QueuedTask, error) {
+	var entry domain.QueuedTask
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// Sorting by an expression reads every ready row, so pick the
+		// candidates without locking and only lock among those
+		var candidates []uint
+		err := ready(tx.Model(&domain.QueuedTask{}), now).
+			Where("queue = ? AND user_id = ?", queue, userID).
+			Clauses(r.claimOrder(now)).
+			Limit(claimCandidates).
+			Pluck("id", &candidates).Error
+		if err != nil {
+			return err
+		}
+		if len(candidates) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		err = ready(tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}), now).
+			Where("id IN ?", candidates).
+			Clauses(r.claimOrder(now)).
+			Take(&entry).Error
+		if err != nil {
+			return err
+		}
+
+		expiresAt := now.Add(lease)
+		entry.LeaseOwner = owner
+		entry.LeaseExpiresAt = &expiresAt
+		return tx.Model(&entry).Updates(map[string]interface{}{
+			"lease_owner":      entry.LeaseOwner,
+			"lease_expires_at": entry.LeaseExpiresAt,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrQueueEmpty
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *taskQueueRepository) ExtendLease(ctx context.Context, entry *domain.QueuedTask, lease time.Duration) error {
+	expiresAt := time.Now().Add(lease)
+	result := r.db.WithContext(ctx).Model(&domain.QueuedTask{}).
+		Where("id = ? AND lease_owner = ?", entry.ID, entry.LeaseOwner).
+		Update("lease_expires_at", expiresAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrLeaseLost
+	}
+	entry.LeaseExpiresAt = &expiresAt
+	return nil
+}
+
+func (r *taskQueueRepository) Complete(ctx context.Context, entry *domain.QueuedTask) error {
+	result := r.db.WithContext(ctx).Where("lease_owner = ?", entry.LeaseOwner).Delete(&domain.QueuedTask{}, entry.ID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrLeaseLost
+	}
+	return nil
+}
+
+// Retry releases the lease on an entry and makes it claimable again at availableAt
+func (r *taskQueueRepository) Retry(ctx context.Context, entry *domain.QueuedTask, availableAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&domain.QueuedTask{}).
+		Where("id = ? AND lease_owner = ?", entry.ID, entry.LeaseOwner).
+		Updates(map[string]interface{}{
+			"lease_owner":      "",
+			"lease_expires_at": nil,
+			"available_at":     availableAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrLeaseLost
+	}
+	entry.LeaseOwner = ""
+	entry.LeaseExpiresAt = nil
+	entry.AvailableAt = availableAt
+	return nil
+}
+
+func (r *taskQueueRepository) FindByTaskID(ctx context.Context, taskID uint) (*domain.QueuedTask, error) {
+	var entry domain.QueuedTask
+	err := r.db.WithContext(ctx).Where("task_id = ?", taskID).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrTaskNotQueued
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *taskQueueRepository) Remove(ctx context.Context, taskID uint) error {
+	return r.db.WithContext(ctx).Where("task_id = ?", taskID).Delete(&domain.QueuedTask{}).Error
+}
+
+func (r *taskQueueRepository) Depth(ctx context.Context, queue string) (int64, error) {
+	var depth int64
+	now := time.Now()
+	err := r.db.WithContext(ctx).Model(&domain.QueuedTask{}).
+		Where("queue = ? AND available_at <= ?", queue, now).
+		Where("lease_expires_at IS NULL OR lease_expires_at < ?", now).
+		Count(&depth).Error
+	return depth, err
+}
+
+func (r *taskQueueRepository) Shares(ctx context.Context, queue string) ([]domain.UserShare, error) {
+	var shares []domain.UserShare
+	now := time.Now()
+	err := r.db.WithContext(ctx).Model(&domain.QueuedTask{}).
+		Select(`user_id,
+			SUM(CASE WHEN lease_expires_at >= ? THEN 1 ELSE 0 END) AS in_flight,
+			SUM(CASE WHEN (lease_expires_at IS NULL OR lease_expires_at < ?) AND available_at <= ? THEN 1 ELSE 0 END) AS ready,
+			MIN(CASE WHEN (lease_expires_at IS NULL OR lease_expires_at < ?) AND available_at <= ? THEN available_at END) AS oldest_ready`,
+			now, now, now, now, now).
+		Where("queue = ?", queue).
+		Group("user_id").
+		Having("in_flight > 0 OR ready > 0").
+		Scan(&shares).Error
+	return shares, err
+}
+
+func (r *taskQueueRepository) Counts(ctx context.Context) (map[string]domain.QueueCounts, error) {
+	var rows []struct {
+		Queue    string
+		Depth    int64
+		InFlight int64
+	}
+	now := time.Now()
+	err := r.db.WithContext(ctx).Model(&domain.QueuedTask{}).
+		Select(`queue,
+			SUM(CASE WHEN lease_expires_at IS NULL OR lease_expires_at < ? THEN 1 ELSE 0 END) AS depth,
+			SUM(CASE WHEN lease_expires_at >= ? THEN 1 ELSE 0 END) AS in_flight`, now, now).
+		Group("queue").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]domain.QueueCounts, len(rows))
+	for _, row := range rows {
+		counts[row.Queue] = domain.QueueCounts{Depth: row.Depth, InFlight: row.InFlight}
+	}
+	return counts, nil
+}
+
+func (r *taskQueueRepository) Positions(ctx context.Context, taskIDs []uint) (map[uint]int, error) {
+	positions := make(map[uint]int, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return positions, nil
+	}
+
+	var rows []struct {
+		TaskID   uint
+		Position int
+	}
+	now := time.Now()
+	err := r.db.WithContext(ctx).Raw(`SELECT task_id, position FROM (
+			SELECT task_id, ROW_NUMBER() OVER (
+				PARTITION BY queue, user_id
+				ORDER BY priority + FLOOR(TIMESTAMPDIFF(SECOND, available_at, ?) / ?) DESC, id
+			) AS position
+			FROM task_queue
+			WHERE (lease_expires_at IS NULL OR lease_expires_at < ?) AND available_at <= ?
+		) ranked WHERE task_id IN ?`, now, r.agingSeconds, now, now, taskIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		positions[row.TaskID] = row.Position
+	}
+	return positions, nil
+}
@@ -1,8 +1,16 @@
 package mysql
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"golangwithgin/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+	"time"
 )
 
 type taskRepository struct {
@@ -14,28 +22,228 @@ func NewTaskRepository(db *gorm.DB) domain.TaskRepository {
 	return &taskRepository{db: db}
 }
 
-func (r *taskRepository) Create(task *domain.Task) error {
-	return r.db.Create(task).Error
+// Create stores a task along with its dependencies. Tasks with dependencies
+// are created blocked, others pending.
+func (r *taskRepository) Create(ctx context.Context, task *domain.Task) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createTask(tx, task)
+	})
 }
 
-func (r *taskRepository) Update(task *domain.Task) error {
-	return r.db.Save(task).Error
+func createTask(tx *gorm.DB, task *domain.Task) error {
+	initial := domain.TaskStatusPending
+	if len(task.DependsOn) > 0 {
+		initial = domain.TaskStatusBlocked
+	}
+	if task.Status != initial {
+		return fmt.Errorf("%w: tasks must be created as %s, not %q", domain.ErrInvalidTaskStatus, initial, task.Status)
+	}
+
+	if err := tx.Create(task).Error; err != nil {
+		return err
+	}
+	if len(task.DependsOn) == 0 {
+		return nil
+	}
+
+	dependencies := make([]domain.TaskDependency, len(task.DependsOn))
+	for i, id := range task.DependsOn {
+		dependencies[i] = domain.TaskDependency{TaskID: task.ID, DependsOnID: id}
+	}
+	return tx.Create(&dependencies).Error
 }
 
-func (r *taskRepository) FindByID(id uint) (*domain.Task, error) {
+// Update saves the status and update time of a task along with the given
+// columns, scoped to the task's owner. Other columns keep their stored
+// values, so a stale copy of the task cannot undo a concurrent writer's
+// changes. The stored status is locked and checked against the task status
+// state machine, so concurrent writers cannot move a task through an
+// illegal transition.
+func (r *taskRepository) Update(ctx context.Context, task *domain.Task, columns ...string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current domain.Task
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "status").
+			Where("user_id = ?", task.UserID).
+			First(&current, task.ID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrTaskNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if !current.Status.CanTransitionTo(task.Status) {
+			return fmt.Errorf("%w: cannot move task from %s to %s", domain.ErrInvalidTaskStatus, current.Status, task.Status)
+		}
+
+		return tx.Model(task).Select(append([]string{"status", "updated_at"}, columns...)).Updates(task).Error
+	})
+}
+
+// UpdateProgress only writes the progress columns, and only while the task
+// is processing, so a late report cannot overwrite a newer status change
+func (r *taskRepository) UpdateProgress(ctx context.Context, task *domain.Task) error {
+	return r.db.WithContext(ctx).Model(&domain.Task{}).
+		Where("id = ? AND status = ?", task.ID, domain.TaskStatusProcessing).
+		UpdateColumns(map[string]interface{}{
+			"progress":         task.Progress,
+			"progress_message": task.ProgressMessage,
+		}).Error
+}
+
+func (r *taskRepository) FindByID(ctx context.Context, id uint) (*domain.Task, error) {
 	var task domain.Task
-	err := r.db.First(&task, id).Error
+	err := r.db.WithContext(ctx).First(&task, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrTaskNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 	return &task, nil
 }
 
-func (r *taskRepository) FindAll() ([]*domain.Task, error) {
+func (r *taskRepository) FindByIDForUser(ctx context.Context, id, userID uint) (*domain.Task, error) {
+	var task domain.Task
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&task, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// taskCursor identifies the last task of a page by its sort value and ID
+type taskCursor struct {
+	Value time.Time `json:"v"`
+	ID    uint      `json:"id"`
+}
+
+func encodeTaskCursor(cursor taskCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeTaskCursor(s string) (*taskCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	var cursor taskCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// List returns a page of tasks using keyset pagination on the sort column
+// and ID. The filter's SortBy and SortOrder must already be validated.
+func (r *taskRepository) List(ctx context.Context, filter domain.TaskFilter) (*domain.TaskPage, error) {
+	query := r.db.WithContext(ctx).Model(&domain.Task{}).Where("user_id = ?", filter.UserID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Title != "" {
+		escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(filter.Title)
+		query = query.Where("title LIKE ?", "%"+escaped+"%")
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.UpdatedAfter != nil {
+		query = query.Where("updated_at >= ?", *filter.UpdatedAfter)
+	}
+	if filter.UpdatedBefore != nil {
+		query = query.Where("updated_at < ?", *filter.UpdatedBefore)
+	}
+
+	page := &domain.TaskPage{}
+	if err := query.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		return nil, err
+	}
+
+	comparison := "<"
+	if filter.SortOrder == "asc" {
+		comparison = ">"
+	}
+	if filter.Cursor != "" {
+		cursor, err := decodeTaskCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(
+			fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", filter.SortBy, comparison),
+			cursor.Value, cursor.Value, cursor.ID,
+		)
+	}
+
+	// Fetch one extra row to find out whether another page follows
+	var tasks []*domain.Task
+	err := query.
+		Order(fmt.Sprintf("%s %s, id %s", filter.SortBy, filter.SortOrder, filter.SortOrder)).
+		Limit(filter.Limit + 1).
+		Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tasks) > filter.Limit {
+		tasks = tasks[:filter.Limit]
+		last := tasks[len(tasks)-1]
+		value := last.CreatedAt
+		if filter.SortBy == "updated_at" {
+			value = last.UpdatedAt
+		}
+		page.NextCursor = encodeTaskCursor(taskCursor{Value: value, ID: last.ID})
+	}
+	page.Tasks = tasks
+	return page, nil
+}
+
+func (r *taskRepository) FindByStatus(ctx context.Context, statuses ...domain.TaskStatus) ([]*domain.Task, error) {
 	var tasks []*domain.Task
-	err := r.db.Find(&tasks).Error
+	err := r.db.WithContext(ctx).Where("status IN ?", statuses).Find(&tasks).Error
 	if err != nil {
 		return nil, err
 	}
 	return tasks, nil
-} 
\ No newline at end of file
+}
+
+func (r *taskRepository) FindByIDsForUser(ctx context.Context, ids []uint, userID uint) ([]*domain.Task, error) {
+	var tasks []*domain.Task
+	err := r.db.WithContext(ctx).Where("user_id = ? AND id IN ?", userID, ids).Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *taskRepository) FindDependencies(ctx context.Context, taskID uint) ([]*domain.Task, error) {
+	var tasks []*domain.Task
+	err := r.db.WithContext(ctx).
+		Joins("JOIN task_dependencies ON task_dependencies.depends_on_id = tasks.id").
+		Where("task_dependencies.task_id = ?", taskID).
+		Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *taskRepository) FindBlockedDependents(ctx context.Context, taskID uint) ([]*domain.Task, error) {
+	var tasks []*domain.Task
+	err := r.db.WithContext(ctx).
+		Joins("JOIN task_dependencies ON task_dependencies.task_id = tasks.id").
+		Where("task_dependencies.depends_on_id = ? AND tasks.status = ?", taskID, domain.TaskStatusBlocked).
+		Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
@@ -0,0 +1,46 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"golangwithgin/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type taskResultRepository struct {
+	db *gorm.DB
+}
+
+// NewTaskResultRepository creates a new MySQL-backed task result store
+func NewTaskResultRepository(db *gorm.DB) domain.TaskResultRepository {
+	return &taskResultRepository{db: db}
+}
+
+// Save stores the result of a task, replacing any result of an earlier run
+func (r *taskResultRepository) Save(ctx context.Context, result *domain.TaskResult) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "task_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"data", "output_size", "expires_at", "created_at"}),
+	}).Create(result).Error
+}
+
+// FindByTaskID returns the result of a task unless it has expired
+func (r *taskResultRepository) FindByTaskID(ctx context.Context, taskID uint) (*domain.TaskResult, error) {
+	var result domain.TaskResult
+	err := r.db.WithContext(ctx).Where("task_id = ? AND expires_at > ?", taskID, time.Now()).First(&result).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrResultNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (r *taskResultRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&domain.TaskResult{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,67 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"golangwithgin/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type webhookDeliveryRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookDeliveryRepository creates a new webhook delivery repository
+func NewWebhookDeliveryRepository(db *gorm.DB) domain.WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{db: db}
+}
+
+func (r *webhookDeliveryRepository) Create(ctx context.Context, deliveries []*domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(deliveries).Error
+}
+
+// Claim leases the oldest due delivery. Rows locked by other dispatchers are
+// skipped so concurrent claims do not block each other.
+func (r *webhookDeliveryRepository) Claim(ctx context.Context, lease time.Duration) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", domain.WebhookDeliveryPending, now).
+			Order("id").
+			First(&delivery).Error
+		if err != nil {
+			return err
+		}
+
+		delivery.NextAttemptAt = now.Add(lease)
+		return tx.Model(&delivery).Update("next_attempt_at", delivery.NextAttemptAt).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrQueueEmpty
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *webhookDeliveryRepository) Update(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Model(delivery).Select("*").Omit("created_at").Updates(delivery).Error
+}
+
+// ListByWebhook returns the most recent deliveries to a webhook first
+func (r *webhookDeliveryRepository) ListByWebhook(ctx context.Context, webhookID uint, limit int) ([]*domain.WebhookDelivery, error) {
+	var deliveries []*domain.WebhookDelivery
+	err := r.db.WithContext(ctx).Where("webhook_id = ?", webhookID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
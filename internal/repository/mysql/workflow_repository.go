@@ -0,0 +1,74 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"golangwithgin/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type workflowRepository struct {
+	db *gorm.DB
+}
+
+// NewWorkflowRepository creates a new workflow repository
+func NewWorkflowRepository(db *gorm.DB) domain.WorkflowRepository {
+	return &workflowRepository{db: db}
+}
+
+func (r *workflowRepository) Create(ctx context.Context, workflow *domain.Workflow, tasks []*domain.Task, parents [][]int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workflow).Error; err != nil {
+			return err
+		}
+
+		for i, task := range tasks {
+			task.WorkflowID = &workflow.ID
+			task.DependsOn = make([]uint, len(parents[i]))
+			for j, parent := range parents[i] {
+				task.DependsOn[j] = tasks[parent].ID
+			}
+			if err := createTask(tx, task); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *workflowRepository) FindByIDForUser(ctx context.Context, id, userID uint) (*domain.Workflow, error) {
+	var workflow domain.Workflow
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&workflow, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrWorkflowNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.db.WithContext(ctx).Where("workflow_id = ?", id).Order("id").Find(&workflow.Tasks).Error; err != nil {
+		return nil, err
+	}
+
+	var dependencies []domain.TaskDependency
+	err = r.db.WithContext(ctx).
+		Joins("JOIN tasks ON tasks.id = task_dependencies.task_id").
+		Where("tasks.workflow_id = ?", id).
+		Order("task_dependencies.depends_on_id").
+		Find(&dependencies).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]*domain.Task, len(workflow.Tasks))
+	for _, task := range workflow.Tasks {
+		byID[task.ID] = task
+	}
+	for _, dependency := range dependencies {
+		if task, ok := byID[dependency.TaskID]; ok {
+			task.DependsOn = append(task.DependsOn, dependency.DependsOnID)
+		}
+	}
+	return &workflow, nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golangwithgin/internal/domain"
+	"sync"
+	"time"
+)
+
+// DependencyResolver starts blocked tasks once their dependencies finish
+// and cascades failures to them according to their dependency policy. It
+// wraps the publisher every status change goes through, so it sees tasks
+// being blocked and finishing without the rest of the code knowing.
+type DependencyResolver struct {
+	repository domain.TaskRepository
+	events     domain.TaskEventPublisher
+
+	// processor queues the tasks whose dependencies have completed. The
+	// processor publishes through the resolver, so it is set afterwards.
+	mu        sync.RWMutex
+	processor domain.TaskProcessor
+}
+
+// NewDependencyResolver creates a resolver that forwards every event to
+// events. It starts resolving tasks once SetProcessor is called.
+func NewDependencyResolver(repository domain.TaskRepository, events domain.TaskEventPublisher) *DependencyResolver {
+	return &DependencyResolver{
+		repository: repository,
+		events:     events,
+	}
+}
+
+// SetProcessor hands the resolver the processor that queues tasks whose
+// dependencies have completed. Tasks published before then stay blocked
+// until ResolveBlocked is called.
+func (r *DependencyResolver) SetProcessor(processor domain.TaskProcessor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.processor = processor
+}
+
+func (r *DependencyResolver) taskProcessor() domain.TaskProcessor {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.processor
+}
+
+// Publish forwards the event, then resolves the task if it was just blocked
+// or its blocked dependents if it finished. Publishers have no context to
+// pass, and the resolution must happen even if the caller has gone away.
+func (r *DependencyResolver) Publish(task *domain.Task) {
+	r.events.Publish(task)
+	if r.taskProcessor() == nil {
+		return
+	}
+
+	ctx := context.Background()
+
+	switch {
+	case task.Status == domain.TaskStatusBlocked:
+		r.resolve(ctx, task)
+	case task.IsFinished():
+		dependents, err := r.repository.FindBlockedDependents(ctx, task.ID)
+		if err != nil {
+			return
+		}
+		for _, dependent := range dependents {
+			r.resolve(ctx, dependent)
+		}
+	}
+}
+
+// PublishProgress forwards the event; progress never affects dependents
+func (r *DependencyResolver) PublishProgress(task *domain.Task) {
+	r.events.PublishProgress(task)
+}
+
+// ResolveBlocked resolves every blocked task, catching up on dependencies
+// that finished while the server was down or before SetProcessor was
+// called
+func (r *DependencyResolver) ResolveBlocked(ctx context.Context) error {
+	tasks, err := r.repository.FindByStatus(ctx, domain.TaskStatusBlocked)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		r.resolve(ctx, task)
+	}
+	return nil
+}
+
+// resolve moves a blocked task on if its dependencies allow it, queueing it
+// through the processor so that an idle worker picks it up right away. The
+// state machine lets only one of several concurrent resolutions through.
+func (r *DependencyResolver) resolve(ctx context.Context, task *domain.Task) {
+	parents, err := r.repository.FindDependencies(ctx, task.ID)
+	if err != nil {
+		return
+	}
+
+	status, reason, ok := resolveDependencies(task.DependencyPolicy, parents)
+	if !ok {
+		return
+	}
+
+	task.Status = status
+	task.LastError = reason
+	task.UpdatedAt = time.Now()
+	err = r.repository.Update(ctx, task, "last_error")
+	if errors.Is(err, domain.ErrInvalidTaskStatus) {
+		// Already resolved or cancelled
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	if status == domain.TaskStatusPending && r.taskProcessor().Process(ctx, task) != nil {
+		task.Status = domain.TaskStatusFailed
+		task.LastError = "could not queue task"
+		task.UpdatedAt = time.Now()
+		if r.repository.Update(ctx, task, "last_error") != nil {
+			return
+		}
+	}
+	r.Publish(task)
+}
+
+// resolveDependencies decides the next status of a blocked task from its
+// dependencies. It reports false while the task has to keep waiting.
+func resolveDependencies(policy domain.DependencyPolicy, parents []*domain.Task) (domain.TaskStatus, string, bool) {
+	waiting := false
+	for _, parent := range parents {
+		switch {
+		case parent.Status == domain.TaskStatusCompleted:
+		case !parent.IsFinished():
+			waiting = true
+		case policy == domain.DependencyPolicyIgnore:
+		case policy == domain.DependencyPolicyFail:
+			return domain.TaskStatusFailed, fmt.Sprintf("dependency %d %s", parent.ID, parent.Status), true
+		default:
+			return domain.TaskStatusCancelled, fmt.Sprintf("dependency %d %s", parent.ID, parent.Status), true
+		}
+	}
+	if waiting {
+		return "", "", false
+	}
+	return domain.TaskStatusPending, "", true
+}
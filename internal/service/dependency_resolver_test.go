@@ -0,0 +1,101 @@
+package service
+
+import (
+	"golangwithgin/internal/domain"
+	"golangwithgin/internal/domain/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDependencyResolver(t *testing.T) (*DependencyResolver, *mocks.MockTaskRepository, *mocks.MockTaskProcessor, *mocks.MockTaskEventPublisher) {
+	ctrl := gomock.NewController(t)
+	repository := mocks.NewMockTaskRepository(ctrl)
+	processor := mocks.NewMockTaskProcessor(ctrl)
+	events := mocks.NewMockTaskEventPublisher(ctrl)
+	resolver := NewDependencyResolver(repository, events)
+	resolver.SetProcessor(processor)
+	return resolver, repository, processor, events
+}
+
+func TestDependencyResolver_QueuesTaskOnceDependenciesComplete(t *testing.T) {
+	resolver, repository, processor, events := newDependencyResolver(t)
+	parent := &domain.Task{ID: 1, Status: domain.TaskStatusCompleted}
+	child := &domain.Task{ID: 2, Status: domain.TaskStatusBlocked}
+
+	events.EXPECT().Publish(parent)
+	repository.EXPECT().FindBlockedDependents(gomock.Any(), uint(1)).Return([]*domain.Task{child}, nil)
+	repository.EXPECT().FindDependencies(gomock.Any(), uint(2)).Return([]*domain.Task{parent, {ID: 3, Status: domain.TaskStatusCompleted}}, nil)
+	repository.EXPECT().Update(gomock.Any(), child, "last_error").Return(nil)
+	processor.EXPECT().Process(gomock.Any(), child).Return(nil)
+	events.EXPECT().Publish(child)
+
+	resolver.Publish(parent)
+	assert.Equal(t, domain.TaskStatusPending, child.Status)
+}
+
+func TestDependencyResolver_WaitsForUnfinishedDependencies(t *testing.T) {
+	resolver, repository, _, events := newDependencyResolver(t)
+	child := &domain.Task{ID: 2, Status: domain.TaskStatusBlocked}
+
+	events.EXPECT().Publish(child)
+	repository.EXPECT().FindDependencies(gomock.Any(), uint(2)).Return([]*domain.Task{
+		{ID: 1, Status: domain.TaskStatusCompleted},
+		{ID: 3, Status: domain.TaskStatusProcessing},
+	}, nil)
+
+	resolver.Publish(child)
+	assert.Equal(t, domain.TaskStatusBlocked, child.Status)
+}
+
+func TestDependencyResolver_CascadesFailure(t *testing.T) {
+	resolver, repository, _, events := newDependencyResolver(t)
+	parent := &domain.Task{ID: 1, Status: domain.TaskStatusDead}
+	child := &domain.Task{ID: 2, Status: domain.TaskStatusBlocked}
+	grandchild := &domain.Task{ID: 3, Status: domain.TaskStatusBlocked, DependencyPolicy: domain.DependencyPolicyFail}
+
+	events.EXPECT().Publish(parent)
+	repository.EXPECT().FindBlockedDependents(gomock.Any(), uint(1)).Return([]*domain.Task{child}, nil)
+	repository.EXPECT().FindDependencies(gomock.Any(), uint(2)).Return([]*domain.Task{parent}, nil)
+	repository.EXPECT().Update(gomock.Any(), child, "last_error").Return(nil)
+	events.EXPECT().Publish(child)
+	repository.EXPECT().FindBlockedDependents(gomock.Any(), uint(2)).Return([]*domain.Task{grandchild}, nil)
+	repository.EXPECT().FindDependencies(gomock.Any(), uint(3)).Return([]*domain.Task{child}, nil)
+	repository.EXPECT().Update(gomock.Any(), grandchild, "last_error").Return(nil)
+	events.EXPECT().Publish(grandchild)
+	repository.EXPECT().FindBlockedDependents(gomock.Any(), uint(3)).Return(nil, nil)
+
+	resolver.Publish(parent)
+	assert.Equal(t, domain.TaskStatusCancelled, child.Status)
+	assert.Equal(t, "dependency 1 dead", child.LastError)
+	assert.Equal(t, domain.TaskStatusFailed, grandchild.Status)
+	assert.Equal(t, "dependency 2 cancelled", grandchild.LastError)
+}
+
+func TestDependencyResolver_IgnorePolicyRunsAfterFailure(t *testing.T) {
+	resolver, repository, processor, events := newDependencyResolver(t)
+	child := &domain.Task{ID: 2, Status: domain.TaskStatusBlocked, DependencyPolicy: domain.DependencyPolicyIgnore}
+
+	events.EXPECT().Publish(child).Times(2)
+	repository.EXPECT().FindDependencies(gomock.Any(), uint(2)).Return([]*domain.Task{{ID: 1, Status: domain.TaskStatusFailed}}, nil)
+	repository.EXPECT().Update(gomock.Any(), child, "last_error").Return(nil)
+	processor.EXPECT().Process(gomock.Any(), child).Return(nil)
+
+	resolver.Publish(child)
+	assert.Equal(t, domain.TaskStatusPending, child.Status)
+}
+
+func TestDependencyResolver_SkipsTasksResolvedElsewhere(t *testing.T) {
+	resolver, repository, _, events := newDependencyResolver(t)
+	parent := &domain.Task{ID: 1, Status: domain.TaskStatusCompleted}
+	child := &domain.Task{ID: 2, Status: domain.TaskStatusBlocked}
+
+	events.EXPECT().Publish(parent)
+	repository.EXPECT().FindBlockedDependents(gomock.Any(), uint(1)).Return([]*domain.Task{child}, nil)
+	repository.EXPECT().FindDependencies(gomock.Any(), uint(2)).Return([]*domain.Task{parent}, nil)
+	repository.EXPECT().Update(gomock.Any(), child, "last_error").Return(domain.ErrInvalidTaskStatus)
+
+	// Neither queued nor published again
+	resolver.Publish(parent)
+}
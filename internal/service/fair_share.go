@@ -0,0 +1,70 @@
+package service
+
+import (
+	"golangwithgin/internal/domain"
+	"sort"
+	"time"
+)
+
+// fairOrder returns the users with ready tasks in the order their tasks
+// should be tried: those running the fewest tasks first, then those served
+// least recently, so that users take turns, then those whose tasks have
+// waited longest. Users already running limit tasks are left out; a limit
+// of zero means no limit.
+func fairOrder(shares []domain.UserShare, limit int, served map[uint]time.Time) []uint {
+	eligible := make([]domain.UserShare, 0, len(shares))
+	for _, share := range shares {
+		if share.Ready > 0 && (limit <= 0 || share.InFlight < int64(limit)) {
+			eligible = append(eligible, share)
+		}
+	}
+
+	sort.Slice(eligible, func(i, j int) bool {
+		a, b := eligible[i], eligible[j]
+		if a.InFlight != b.InFlight {
+			return a.InFlight < b.InFlight
+		}
+		if servedA, servedB := served[a.UserID], served[b.UserID]; !servedA.Equal(servedB) {
+			return servedA.Before(servedB)
+		}
+		if oldestA, oldestB := waitingSince(a), waitingSince(b); !oldestA.Equal(oldestB) {
+			return oldestA.Before(oldestB)
+		}
+		return a.UserID < b.UserID
+	})
+
+	users := make([]uint, len(eligible))
+	for i, share := range eligible {
+		users[i] = share.UserID
+	}
+	return users
+}
+
+// claimableBacklog sums up the ready tasks that may start without taking
+// any user past limit, and how long the oldest of them has waited
+func claimableBacklog(shares []domain.UserShare, limit int, now time.Time) *domain.QueueBacklog {
+	backlog := &domain.QueueBacklog{}
+	for _, share := range shares {
+		ready := share.Ready
+		if limit > 0 {
+			ready = min(ready, max(int64(limit)-share.InFlight, 0))
+		}
+		if ready == 0 {
+			continue
+		}
+
+		backlog.Ready += ready
+		if share.OldestReady != nil {
+			backlog.OldestWait = max(backlog.OldestWait, now.Sub(*share.OldestReady))
+		}
+	}
+	return backlog
+}
+
+// waitingSince returns when the user's oldest ready task became claimable
+func waitingSince(share domain.UserShare) time.Time {
+	if share.OldestReady == nil {
+		return time.Time{}
+	}
+	return *share.OldestReady
+}
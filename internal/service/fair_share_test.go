@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"golangwithgin/internal/domain"
+	"golangwithgin/internal/domain/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFairOrder(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Minute)
+
+	tests := []struct {
+		name   string
+		shares []domain.UserShare
+		limit  int
+		served map[uint]time.Time
+		want   []uint
+	}{
+		{"no shares", nil, 0, nil, []uint{}},
+		{"fewest running first", []domain.UserShare{
+			{UserID: 1, Ready: 10000, InFlight: 5, OldestReady: &earlier},
+			{UserID: 2, Ready: 1, InFlight: 0, OldestReady: &now},
+		}, 0, nil, []uint{2, 1}},
+		{"least recently served goes next", []domain.UserShare{
+			{UserID: 1, Ready: 10000, InFlight: 0, OldestReady: &earlier},
+			{UserID: 2, Ready: 1, InFlight: 0, OldestReady: &now},
+		}, 0, map[uint]time.Time{1: now}, []uint{2, 1}},
+		{"longest waiting breaks ties", []domain.UserShare{
+			{UserID: 1, Ready: 1, InFlight: 1, OldestReady: &now},
+			{UserID: 2, Ready: 1, InFlight: 1, OldestReady: &earlier},
+		}, 0, nil, []uint{2, 1}},
+		{"users without ready tasks are skipped", []domain.UserShare{
+			{UserID: 1, InFlight: 2},
+			{UserID: 2, Ready: 3, InFlight: 4, OldestReady: &now},
+		}, 0, nil, []uint{2}},
+		{"users at the limit are skipped", []domain.UserShare{
+			{UserID: 1, Ready: 50, InFlight: 2, OldestReady: &earlier},
+			{UserID: 2, Ready: 1, InFlight: 1, OldestReady: &now},
+		}, 2, nil, []uint{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, fairOrder(tt.shares, tt.limit, tt.served))
+		})
+	}
+}
+
+func TestClaimableBacklog(t *testing.T) {
+	now := time.Now()
+	minuteAgo := now.Add(-time.Minute)
+	secondAgo := now.Add(-time.Second)
+	shares := []domain.UserShare{
+		{UserID: 1, Ready: 10000, InFlight: 2, OldestReady: &minuteAgo},
+		{UserID: 2, Ready: 3, InFlight: 0, OldestReady: &secondAgo},
+	}
+
+	assert.Equal(t, &domain.QueueBacklog{Ready: 10003, OldestWait: time.Minute}, claimableBacklog(shares, 0, now))
+	// The first user is at the limit, so only the second user's tasks count
+	assert.Equal(t, &domain.QueueBacklog{Ready: 2, OldestWait: time.Second}, claimableBacklog(shares, 2, now))
+}
+
+func TestClaim_TakesTurnsBetweenUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	queue := mocks.NewMockTaskQueue(ctrl)
+	now := time.Now()
+	entry := &domain.QueuedTask{ID: 10, TaskID: 1}
+
+	queue.EXPECT().Shares(gomock.Any(), domain.DefaultQueue).Return([]domain.UserShare{
+		{UserID: 1, Ready: 10000, InFlight: 2, OldestReady: &now},
+		{UserID: 2, Ready: 1, InFlight: 0, OldestReady: &now},
+		{UserID: 3, Ready: 5, InFlight: 3, OldestReady: &now},
+	}, nil)
+	// The second user's task is taken by another worker first, and the
+	// third user is at the limit
+	gomock.InOrder(
+		queue.EXPECT().Claim(gomock.Any(), domain.DefaultQueue, uint(2), "owner", taskLeaseDuration).Return(nil, domain.ErrQueueEmpty),
+		queue.EXPECT().Claim(gomock.Any(), domain.DefaultQueue, uint(1), "owner", taskLeaseDuration).Return(entry, nil),
+	)
+
+	processor := &TaskProcessor{queue: queue}
+	pool := newWorkerPool(domain.DefaultQueue, QueueConfig{MinWorkers: 1, MaxWorkers: 1, UserConcurrency: 3})
+
+	claimed, err := processor.claim(context.Background(), pool, "owner")
+	assert.NoError(t, err)
+	assert.Equal(t, entry, claimed)
+
+	// The user served is the last to go among those running as many tasks
+	served := pool.lastServed([]domain.UserShare{{UserID: 1}, {UserID: 2}})
+	assert.Contains(t, served, uint(1))
+	assert.NotContains(t, served, uint(2))
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"golangwithgin/internal/domain"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultTaskType is used for tasks submitted without a type
+const DefaultTaskType = "default"
+
+// TaskHandlerFunc runs a task of the type it is registered for. It must
+// return promptly once ctx is cancelled. A returned error fails the attempt;
+// otherwise the returned result, if any, is stored for the task's owner.
+// Long running handlers can report their progress with ReportProgress.
+type TaskHandlerFunc func(ctx context.Context, task *domain.Task) (*domain.TaskResult, error)
+
+// HandlerRegistry maps task types to the handlers that run them
+type HandlerRegistry struct {
+	mu       sync.RWMutex
+	handlers map[string]TaskHandlerFunc
+}
+
+// NewHandlerRegistry creates an empty handler registry
+func NewHandlerRegistry() *HandlerRegistry {
+	return &HandlerRegistry{
+		handlers: make(map[string]TaskHandlerFunc),
+	}
+}
+
+// Register adds the handler for a task type. It panics if the type is empty
+// or already registered, as both are programming errors.
+func (r *HandlerRegistry) Register(taskType string, handler TaskHandlerFunc) {
+	if taskType == "" {
+		panic("service: task type must not be empty")
+	}
+	if handler == nil {
+		panic(fmt.Sprintf("service: nil handler for task type %q", taskType))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.handlers[taskType]; exists {
+		panic(fmt.Sprintf("service: handler for task type %q already registered", taskType))
+	}
+	r.handlers[taskType] = handler
+}
+
+// Lookup returns the handler registered for a task type
+func (r *HandlerRegistry) Lookup(taskType string) (TaskHandlerFunc, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	handler, ok := r.handlers[taskType]
+	return handler, ok
+}
+
+// Types returns the registered task types in alphabetical order
+func (r *HandlerRegistry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.handlers))
+	for taskType := range r.handlers {
+		types = append(types, taskType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// RegisterDefaultHandlers adds the handlers that ship with the server
+func RegisterDefaultHandlers(r *HandlerRegistry) {
+	r.Register(DefaultTaskType, simulateWork)
+}
+
+// simulateWork stands in for real work on tasks that have no specific type
+func simulateWork(ctx context.Context, task *domain.Task) (*domain.TaskResult, error) {
+	for i, message := range []string{"Halfway there", "Done"} {
+		select {
+		case <-time.After(500 * time.Millisecond):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		ReportProgress(ctx, (i+1)*50, message)
+	}
+
+	data, err := json.Marshal(map[string]string{
+		"message": fmt.Sprintf("Processed task '%s' at %v", task.Title, time.Now().Format(time.RFC3339)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TaskResult{Data: data}, nil
+}
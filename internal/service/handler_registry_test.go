@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"golangwithgin/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerRegistry(t *testing.T) {
+	registry := NewHandlerRegistry()
+	noop := func(context.Context, *domain.Task) (*domain.TaskResult, error) { return nil, nil }
+
+	registry.Register("email", noop)
+	registry.Register("report", noop)
+
+	_, ok := registry.Lookup("email")
+	assert.True(t, ok)
+	_, ok = registry.Lookup("missing")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"email", "report"}, registry.Types())
+}
+
+func TestHandlerRegistry_RejectsDuplicateType(t *testing.T) {
+	registry := NewHandlerRegistry()
+	noop := func(context.Context, *domain.Task) (*domain.TaskResult, error) { return nil, nil }
+
+	registry.Register("email", noop)
+	assert.Panics(t, func() { registry.Register("email", noop) })
+	assert.Panics(t, func() { registry.Register("", noop) })
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"golangwithgin/internal/domain"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultIdempotencyWindow is how long idempotency keys are kept when no
+	// window is configured
+	DefaultIdempotencyWindow = 24 * time.Hour
+
+	maxIdempotencyKeyLength  = 255
+	idempotencyPurgeInterval = time.Hour
+
+	// A reservation that has not completed within this time is assumed to
+	// belong to a request that died, and the key may be reserved again
+	idempotencyLockTimeout = time.Minute
+)
+
+// idempotencyService implements the IdempotencyService interface
+type idempotencyService struct {
+	repository domain.IdempotencyKeyRepository
+	window     time.Duration
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewIdempotencyService creates a service that keeps keys for window and
+// purges expired ones in the background until it is closed
+func NewIdempotencyService(repository domain.IdempotencyKeyRepository, window time.Duration) domain.IdempotencyService {
+	if window <= 0 {
+		window = DefaultIdempotencyWindow
+	}
+
+	service := &idempotencyService{
+		repository: repository,
+		window:     window,
+		done:       make(chan struct{}),
+	}
+
+	service.wg.Add(1)
+	go service.purge()
+	return service
+}
+
+func (s *idempotencyService) Begin(ctx context.Context, userID uint, key string, request []byte) (*domain.IdempotencyKey, error) {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return nil, domain.ErrInvalidIdempotencyKey
+	}
+
+	sum := sha256.Sum256(request)
+	hash := hex.EncodeToString(sum[:])
+
+	// A second pass is needed when an expired or abandoned key is replaced
+	for attempt := 0; attempt < 2; attempt++ {
+		now := time.Now()
+		record := &domain.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			RequestHash: hash,
+			ExpiresAt:   now.Add(s.window),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		err := s.repository.Create(ctx, record)
+		if err == nil {
+			return record, nil
+		}
+		if !errors.Is(err, domain.ErrIdempotencyKeyExists) {
+			return nil, err
+		}
+
+		existing, err := s.repository.Find(ctx, userID, key)
+		if errors.Is(err, domain.ErrIdempotencyKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		abandoned := !existing.Completed() && existing.CreatedAt.Before(now.Add(-idempotencyLockTimeout))
+		if !existing.ExpiresAt.After(now) || abandoned {
+			if err := s.repository.Delete(ctx, existing.ID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if existing.RequestHash != hash {
+			return nil, domain.ErrIdempotencyKeyReused
+		}
+		if !existing.Completed() {
+			return nil, domain.ErrIdempotencyKeyInProgress
+		}
+		return existing, nil
+	}
+	return nil, domain.ErrIdempotencyKeyInProgress
+}
+
+func (s *idempotencyService) Complete(ctx context.Context, key *domain.IdempotencyKey, code int, body []byte) error {
+	key.ResponseCode = code
+	key.ResponseBody = body
+	key.UpdatedAt = time.Now()
+	return s.repository.Update(ctx, key)
+}
+
+func (s *idempotencyService) Release(ctx context.Context, key *domain.IdempotencyKey) error {
+	return s.repository.Delete(ctx, key.ID)
+}
+
+func (s *idempotencyService) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+	s.wg.Wait()
+}
+
+// purge deletes expired keys now and then until the service is closed
+func (s *idempotencyService) purge() {
+	defer s.wg.Done()
+
+	ctx := context.Background()
+	ticker := time.NewTicker(idempotencyPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		s.repository.DeleteExpired(ctx, time.Now())
+
+		select {
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"golangwithgin/internal/domain"
+	"golangwithgin/internal/domain/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newIdempotencyService(t *testing.T) (domain.IdempotencyService, *mocks.MockIdempotencyKeyRepository) {
+	ctrl := gomock.NewController(t)
+	repository := mocks.NewMockIdempotencyKeyRepository(ctrl)
+	repository.EXPECT().DeleteExpired(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+
+	service := NewIdempotencyService(repository, time.Hour)
+	t.Cleanup(service.Close)
+	return service, repository
+}
+
+func TestIdempotencyService_BeginReservesNewKey(t *testing.T) {
+	service, repository := newIdempotencyService(t)
+	repository.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, key *domain.IdempotencyKey) error {
+			assert.Equal(t, uint(1), key.UserID)
+			assert.Equal(t, "key-1", key.Key)
+			assert.Len(t, key.RequestHash, 64)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), key.ExpiresAt, time.Minute)
+			return nil
+		})
+
+	record, err := service.Begin(context.Background(), 1, "key-1", []byte(`{"title":"a"}`))
+	assert.NoError(t, err)
+	assert.False(t, record.Completed())
+}
+
+func TestIdempotencyService_BeginRejectsInvalidKey(t *testing.T) {
+	service, _ := newIdempotencyService(t)
+
+	_, err := service.Begin(context.Background(), 1, strings.Repeat("k", 256), nil)
+	assert.ErrorIs(t, err, domain.ErrInvalidIdempotencyKey)
+}
+
+func TestIdempotencyService_BeginExistingKey(t *testing.T) {
+	service, repository := newIdempotencyService(t)
+
+	// Learn the hash of the original request
+	var original domain.IdempotencyKey
+	repository.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, key *domain.IdempotencyKey) error {
+			original = *key
+			return nil
+		})
+	_, err := service.Begin(context.Background(), 1, "key-1", []byte(`{"title":"a"}`))
+	assert.NoError(t, err)
+
+	original.ID = 5
+	original.ResponseCode = 202
+	original.ResponseBody = domain.JSON(`{"id":9}`)
+	repository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(domain.ErrIdempotencyKeyExists).Times(2)
+	repository.EXPECT().Find(gomock.Any(), uint(1), "key-1").Return(&original, nil).Times(2)
+
+	record, err := service.Begin(context.Background(), 1, "key-1", []byte(`{"title":"a"}`))
+	assert.NoError(t, err)
+	assert.True(t, record.Completed())
+	assert.Equal(t, `{"id":9}`, string(record.ResponseBody))
+
+	_, err = service.Begin(context.Background(), 1, "key-1", []byte(`{"title":"b"}`))
+	assert.ErrorIs(t, err, domain.ErrIdempotencyKeyReused)
+}
+
+func TestIdempotencyService_BeginInProgress(t *testing.T) {
+	service, repository := newIdempotencyService(t)
+
+	var reserved domain.IdempotencyKey
+	repository.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, key *domain.IdempotencyKey) error {
+			reserved = *key
+			return nil
+		})
+	_, err := service.Begin(context.Background(), 1, "key-1", []byte(`{}`))
+	assert.NoError(t, err)
+
+	repository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(domain.ErrIdempotencyKeyExists)
+	repository.EXPECT().Find(gomock.Any(), uint(1), "key-1").Return(&reserved, nil)
+
+	_, err = service.Begin(context.Background(), 1, "key-1", []byte(`{}`))
+	assert.ErrorIs(t, err, domain.ErrIdempotencyKeyInProgress)
+}
+
+func TestIdempotencyService_BeginReplacesExpiredKey(t *testing.T) {
+	service, repository := newIdempotencyService(t)
+
+	expired := &domain.IdempotencyKey{
+		ID:           5,
+		UserID:       1,
+		Key:          "key-1",
+		RequestHash:  "other",
+		ResponseCode: 202,
+		ExpiresAt:    time.Now().Add(-time.Second),
+	}
+	gomock.InOrder(
+		repository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(domain.ErrIdempotencyKeyExists),
+		repository.EXPECT().Find(gomock.Any(), uint(1), "key-1").Return(expired, nil),
+		repository.EXPECT().Delete(gomock.Any(), uint(5)).Return(nil),
+		repository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil),
+	)
+
+	record, err := service.Begin(context.Background(), 1, "key-1", []byte(`{}`))
+	assert.NoError(t, err)
+	assert.False(t, record.Completed())
+}
+
+func TestIdempotencyService_CompleteStoresResponse(t *testing.T) {
+	service, repository := newIdempotencyService(t)
+	repository.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, key *domain.IdempotencyKey) error {
+			assert.Equal(t, 202, key.ResponseCode)
+			assert.Equal(t, `{"id":9}`, string(key.ResponseBody))
+			return nil
+		})
+
+	assert.NoError(t, service.Complete(context.Background(), &domain.IdempotencyKey{ID: 5}, 202, []byte(`{"id":9}`)))
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"golangwithgin/internal/domain"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultProgressInterval is the minimum time between two progress
+	// writes of a task when the configuration does not set one
+	DefaultProgressInterval = time.Second
+	maxProgressMessage      = 255
+)
+
+type progressKey struct{}
+
+// ReportProgress records how far along the task run by the calling handler
+// is, as a percentage and a short message. Handlers may report as often as
+// they like: writes are rate limited, with the latest report always saved.
+// Outside a handler it does nothing.
+func ReportProgress(ctx context.Context, percent int, message string) {
+	if reporter, ok := ctx.Value(progressKey{}).(*progressReporter); ok {
+		reporter.report(percent, message)
+	}
+}
+
+// progressReporter saves and publishes the progress of one attempt of a
+// task at most once per interval. A report arriving too soon is held back
+// and written when the interval has passed, unless a newer one replaces it.
+type progressReporter struct {
+	repository domain.TaskRepository
+	events     domain.TaskEventPublisher
+	interval   time.Duration
+
+	mu      sync.Mutex
+	task    domain.Task
+	written time.Time
+	timer   *time.Timer
+	closed  bool
+
+	// writing is held during a write, and taken before mu is released so
+	// that writes land in order; close waits on it for the last one
+	writing sync.Mutex
+}
+
+func newProgressReporter(repository domain.TaskRepository, events domain.TaskEventPublisher, interval time.Duration, task *domain.Task) *progressReporter {
+	return &progressReporter{
+		repository: repository,
+		events:     events,
+		interval:   interval,
+		task:       *task,
+	}
+}
+
+// withProgress returns a context through which handlers report to r
+func (r *progressReporter) withProgress(ctx context.Context) context.Context {
+	return context.WithValue(ctx, progressKey{}, r)
+}
+
+func (r *progressReporter) report(percent int, message string) {
+	percent = min(max(percent, 0), 100)
+	if len(message) > maxProgressMessage {
+		message = message[:maxProgressMessage]
+		for !utf8.ValidString(message) {
+			message = message[:len(message)-1]
+		}
+	}
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+	r.task.Progress = percent
+	r.task.ProgressMessage = message
+
+	if wait := r.interval - time.Since(r.written); wait > 0 {
+		if r.timer == nil {
+			r.timer = time.AfterFunc(wait, r.flush)
+		}
+		r.mu.Unlock()
+		return
+	}
+	r.write()
+}
+
+// flush writes a report that was held back
+func (r *progressReporter) flush() {
+	r.mu.Lock()
+	r.timer = nil
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+	r.write()
+}
+
+// write saves and publishes the latest report. The caller holds r.mu, which
+// is released once the report is copied so that other reports do not wait
+// on the database.
+func (r *progressReporter) write() {
+	r.written = time.Now()
+	task := r.task
+	r.writing.Lock()
+	defer r.writing.Unlock()
+	r.mu.Unlock()
+
+	// The write outlives the handler's context, which may have been
+	// cancelled by the time a held back report is flushed
+	if err := r.repository.UpdateProgress(context.Background(), &task); err != nil {
+		return
+	}
+	r.events.PublishProgress(&task)
+}
+
+// close stops the reporter and returns the latest report, so that it is
+// kept when the attempt's outcome is saved
+func (r *progressReporter) close() (int, string) {
+	r.mu.Lock()
+	r.closed = true
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	progress, message := r.task.Progress, r.task.ProgressMessage
+	r.mu.Unlock()
+
+	// A write still in flight must not land after the outcome is saved
+	r.writing.Lock()
+	defer r.writing.Unlock()
+	return progress, message
+}
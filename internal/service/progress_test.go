@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"golangwithgin/internal/domain"
+	"golangwithgin/internal/domain/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressReporter_HoldsBackReportsWithinInterval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repository := mocks.NewMockTaskRepository(ctrl)
+	events := mocks.NewMockTaskEventPublisher(ctrl)
+
+	written := make(chan domain.Task, 2)
+	repository.EXPECT().
+		UpdateProgress(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, task *domain.Task) error {
+			written <- *task
+			return nil
+		}).
+		Times(2)
+	events.EXPECT().PublishProgress(gomock.Any()).Times(2)
+
+	reporter := newProgressReporter(repository, events, 50*time.Millisecond, &domain.Task{ID: 1, Status: domain.TaskStatusProcessing})
+	ctx := reporter.withProgress(context.Background())
+	ReportProgress(ctx, 10, "one")
+	ReportProgress(ctx, 20, "two")
+	ReportProgress(ctx, 30, "three")
+
+	first := <-written
+	assert.Equal(t, 10, first.Progress)
+	assert.Equal(t, "one", first.ProgressMessage)
+
+	// The held back reports collapse into the latest one
+	second := <-written
+	assert.Equal(t, 30, second.Progress)
+	assert.Equal(t, "three", second.ProgressMessage)
+
+	progress, message := reporter.close()
+	assert.Equal(t, 30, progress)
+	assert.Equal(t, "three", message)
+}
+
+func TestProgressReporter_ClampsReports(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repository := mocks.NewMockTaskRepository(ctrl)
+	events := mocks.NewMockTaskEventPublisher(ctrl)
+	repository.EXPECT().UpdateProgress(gomock.Any(), gomock.Any()).Return(nil)
+	events.EXPECT().PublishProgress(gomock.Any())
+
+	reporter := newProgressReporter(repository, events, time.Hour, &domain.Task{ID: 1})
+	reporter.report(150, strings.Repeat("é", maxProgressMessage))
+
+	progress, message := reporter.close()
+	assert.Equal(t, 100, progress)
+	assert.LessOrEqual(t, len(message), maxProgressMessage)
+	assert.True(t, strings.HasPrefix(strings.Repeat("é", maxProgressMessage), message))
+}
+
+func TestReportProgress_OutsideHandler(t *testing.T) {
+	assert.NotPanics(t, func() { ReportProgress(context.Background(), 50, "halfway") })
+}
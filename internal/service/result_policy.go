@@ -0,0 +1,44 @@
+package service
+
+import (
+	"fmt"
+	"golangwithgin/internal/domain"
+	"time"
+)
+
+// ResultPolicy limits the size of stored task results and how long they are
+// kept
+type ResultPolicy struct {
+	MaxSize   int
+	Retention time.Duration
+}
+
+// DefaultResultPolicy is used for any field left unset in a ResultPolicy
+var DefaultResultPolicy = ResultPolicy{
+	MaxSize:   1 << 20,
+	Retention: 7 * 24 * time.Hour,
+}
+
+// withDefaults fills unset fields from DefaultResultPolicy
+func (p ResultPolicy) withDefaults() ResultPolicy {
+	if p.MaxSize <= 0 {
+		p.MaxSize = DefaultResultPolicy.MaxSize
+	}
+	if p.Retention <= 0 {
+		p.Retention = DefaultResultPolicy.Retention
+	}
+	return p
+}
+
+// prepare checks a handler's result against the size limit and stamps it
+// with its task and expiry time
+func (p ResultPolicy) prepare(task *domain.Task, result *domain.TaskResult, now time.Time) error {
+	if len(result.Data) > p.MaxSize {
+		return fmt.Errorf("%w: %d bytes exceeds the limit of %d", domain.ErrResultTooLarge, len(result.Data), p.MaxSize)
+	}
+	result.ID = 0
+	result.TaskID = task.ID
+	result.CreatedAt = now
+	result.ExpiresAt = now.Add(p.Retention)
+	return nil
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+	}
+
+	assert.Equal(t, time.Second, policy.Backoff(1))
+	assert.Equal(t, 2*time.Second, policy.Backoff(2))
+	assert.Equal(t, 4*time.Second, policy.Backoff(3))
+	assert.Equal(t, 8*time.Second, policy.Backoff(4))
+	assert.Equal(t, 10*time.Second, policy.Backoff(5))
+	assert.Equal(t, 10*time.Second, policy.Backoff(100))
+}
+
+func TestRetryPolicy_WithDefaults(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 7}.withDefaults()
+
+	assert.Equal(t, 7, policy.MaxAttempts)
+	assert.Equal(t, DefaultRetryPolicy.InitialBackoff, policy.InitialBackoff)
+	assert.Equal(t, DefaultRetryPolicy.MaxBackoff, policy.MaxBackoff)
+	assert.Equal(t, DefaultRetryPolicy.Multiplier, policy.Multiplier)
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"golangwithgin/internal/domain"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// DefaultScheduleTimezone is used for schedules created without a timezone
+const DefaultScheduleTimezone = "UTC"
+
+// scheduleService implements the ScheduleService interface
+type scheduleService struct {
+	repository domain.ScheduleRepository
+	registry   *HandlerRegistry
+}
+
+// NewScheduleService creates a new schedule service. Task templates are
+// checked against registry so that runs do not fail on an unknown type.
+func NewScheduleService(repository domain.ScheduleRepository, registry *HandlerRegistry) domain.ScheduleService {
+	return &scheduleService{
+		repository: repository,
+		registry:   registry,
+	}
+}
+
+// Create stores a schedule and computes its first run
+func (s *scheduleService) Create(ctx context.Context, schedule *domain.Schedule) error {
+	if err := s.validate(schedule); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	schedule.ID = 0
+	schedule.LastRunAt = nil
+	schedule.LastTaskID = nil
+	schedule.LastError = ""
+	schedule.NextRunAt = nil
+	if !schedule.Paused {
+		next, _ := NextScheduleRun(schedule, now)
+		schedule.NextRunAt = &next
+	}
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+	return s.repository.Create(ctx, schedule)
+}
+
+func (s *scheduleService) Get(ctx context.Context, userID, id uint) (*domain.Schedule, error) {
+	return s.repository.FindByIDForUser(ctx, id, userID)
+}
+
+func (s *scheduleService) List(ctx context.Context, userID uint) ([]*domain.Schedule, error) {
+	return s.repository.ListByUser(ctx, userID)
+}
+
+// Update replaces a schedule's settings and recomputes its next run. The
+// run history and paused state are kept.
+func (s *scheduleService) Update(ctx context.Context, schedule *domain.Schedule) error {
+	if err := s.validate(schedule); err != nil {
+		return err
+	}
+
+	current, err := s.repository.FindByIDForUser(ctx, schedule.ID, schedule.UserID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	schedule.Paused = current.Paused
+	schedule.LastRunAt = current.LastRunAt
+	schedule.LastTaskID = current.LastTaskID
+	schedule.LastError = current.LastError
+	schedule.NextRunAt = nil
+	if !schedule.Paused {
+		next, _ := NextScheduleRun(schedule, now)
+		schedule.NextRunAt = &next
+	}
+	schedule.CreatedAt = current.CreatedAt
+	schedule.UpdatedAt = now
+	return s.repository.Update(ctx, schedule)
+}
+
+func (s *scheduleService) Delete(ctx context.Context, userID, id uint) error {
+	return s.repository.Delete(ctx, id, userID)
+}
+
+// Pause stops a schedule from running until it is resumed
+func (s *scheduleService) Pause(ctx context.Context, userID, id uint) (*domain.Schedule, error) {
+	schedule, err := s.repository.FindByIDForUser(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Paused {
+		return schedule, nil
+	}
+
+	schedule.Paused = true
+	schedule.NextRunAt = nil
+	schedule.UpdatedAt = time.Now()
+	if err := s.repository.Update(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// Resume restarts a paused schedule from its next run after now. Runs missed
+// while paused are skipped.
+func (s *scheduleService) Resume(ctx context.Context, userID, id uint) (*domain.Schedule, error) {
+	schedule, err := s.repository.FindByIDForUser(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !schedule.Paused {
+		return schedule, nil
+	}
+
+	next, err := NextScheduleRun(schedule, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	schedule.Paused = false
+	schedule.NextRunAt = &next
+	schedule.UpdatedAt = time.Now()
+	if err := s.repository.Update(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *scheduleService) validate(schedule *domain.Schedule) error {
+	if schedule.Timezone == "" {
+		schedule.Timezone = DefaultScheduleTimezone
+	}
+	if _, err := NextScheduleRun(schedule, time.Now()); err != nil {
+		return err
+	}
+
+	if schedule.Task.Title == "" {
+		return fmt.Errorf("%w: task title is required", domain.ErrInvalidSchedule)
+	}
+	task := schedule.Task.NewTask(schedule.UserID)
+	if err := validateTask(task, s.registry); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidSchedule, err)
+	}
+	schedule.Task.Type = task.Type
+	return nil
+}
+
+// NextScheduleRun returns the first time after the given one at which the
+// schedule's cron expression fires in its timezone
+func NextScheduleRun(schedule *domain.Schedule, after time.Time) (time.Time, error) {
+	expression, err := cron.ParseStandard(schedule.CronExpression)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %v", domain.ErrInvalidSchedule, err)
+	}
+	location, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: unknown timezone %q", domain.ErrInvalidSchedule, schedule.Timezone)
+	}
+
+	next := expression.Next(after.In(location))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("%w: cron expression never fires", domain.ErrInvalidSchedule)
+	}
+	return next.UTC(), nil
+}
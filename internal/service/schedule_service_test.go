@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"golangwithgin/internal/domain"
+	"golangwithgin/internal/domain/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newScheduleService(t *testing.T) (domain.ScheduleService, *mocks.MockScheduleRepository) {
+	ctrl := gomock.NewController(t)
+	repository := mocks.NewMockScheduleRepository(ctrl)
+	registry := NewHandlerRegistry()
+	RegisterDefaultHandlers(registry)
+	return NewScheduleService(repository, registry), repository
+}
+
+func TestNextScheduleRun_UsesTimezone(t *testing.T) {
+	schedule := &domain.Schedule{CronExpression: "0 2 * * *", Timezone: "America/New_York"}
+	after := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	next, err := NextScheduleRun(schedule, after)
+	assert.NoError(t, err)
+	// 02:00 in New York is 07:00 UTC in winter
+	assert.Equal(t, time.Date(2025, 1, 16, 7, 0, 0, 0, time.UTC), next)
+}
+
+func TestScheduleService_CreateValidates(t *testing.T) {
+	service, _ := newScheduleService(t)
+	task := domain.TaskTemplate{Title: "Report"}
+
+	invalid := []*domain.Schedule{
+		{CronExpression: "every night", Task: task},
+		{CronExpression: "0 2 * * *", Timezone: "Mars/Olympus", Task: task},
+		{CronExpression: "0 2 * * *"},
+		{CronExpression: "0 2 * * *", Task: domain.TaskTemplate{Title: "Report", Type: "missing"}},
+		{CronExpression: "0 2 * * *", Task: domain.TaskTemplate{Title: "Report", Priority: 10}},
+	}
+	for _, schedule := range invalid {
+		assert.ErrorIs(t, service.Create(context.Background(), schedule), domain.ErrInvalidSchedule, schedule.CronExpression)
+	}
+}
+
+func TestScheduleService_CreateComputesNextRun(t *testing.T) {
+	service, repository := newScheduleService(t)
+	repository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	schedule := &domain.Schedule{UserID: 1, CronExpression: "@hourly", Task: domain.TaskTemplate{Title: "Report"}}
+	assert.NoError(t, service.Create(context.Background(), schedule))
+
+	assert.Equal(t, DefaultScheduleTimezone, schedule.Timezone)
+	assert.Equal(t, DefaultTaskType, schedule.Task.Type)
+	if assert.NotNil(t, schedule.NextRunAt) {
+		assert.True(t, schedule.NextRunAt.After(time.Now()))
+		assert.Zero(t, schedule.NextRunAt.Minute())
+	}
+}
+
+func TestScheduleService_PauseAndResume(t *testing.T) {
+	service, repository := newScheduleService(t)
+	next := time.Now().Add(time.Hour)
+	schedule := &domain.Schedule{ID: 3, UserID: 1, CronExpression: "@hourly", Timezone: "UTC", NextRunAt: &next}
+
+	repository.EXPECT().FindByIDForUser(gomock.Any(), uint(3), uint(1)).Return(schedule, nil).Times(2)
+	repository.EXPECT().Update(gomock.Any(), schedule).Return(nil).Times(2)
+
+	paused, err := service.Pause(context.Background(), 1, 3)
+	assert.NoError(t, err)
+	assert.True(t, paused.Paused)
+	assert.Nil(t, paused.NextRunAt)
+
+	resumed, err := service.Resume(context.Background(), 1, 3)
+	assert.NoError(t, err)
+	assert.False(t, resumed.Paused)
+	if assert.NotNil(t, resumed.NextRunAt) {
+		assert.True(t, resumed.NextRunAt.After(time.Now()))
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"golangwithgin/internal/domain"
+	"sync"
+	"time"
+)
+
+const (
+	schedulerPollInterval = time.Second
+	schedulerBatchSize    = 100
+)
+
+// Scheduler submits a task for every due run of the stored schedules. A run
+// is claimed by advancing the schedule's next run before the task is
+// submitted, so runs are never duplicated by a restart or by several server
+// instances; a run interrupted between the two steps is skipped. Runs missed
+// while the server was down are coalesced into a single run.
+type Scheduler struct {
+	repository  domain.ScheduleRepository
+	taskService domain.TaskService
+	wg          sync.WaitGroup
+	stopChan    chan struct{}
+}
+
+// NewScheduler creates a scheduler and starts polling for due schedules
+func NewScheduler(repository domain.ScheduleRepository, taskService domain.TaskService) *Scheduler {
+	scheduler := &Scheduler{
+		repository:  repository,
+		taskService: taskService,
+		stopChan:    make(chan struct{}),
+	}
+
+	scheduler.wg.Add(1)
+	go scheduler.loop()
+	return scheduler
+}
+
+func (s *Scheduler) loop() {
+	defer s.wg.Done()
+
+	ctx := context.Background()
+	ticker := time.NewTicker(schedulerPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.runDue(ctx, time.Now())
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// runDue runs every schedule that is due at now
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) {
+	schedules, err := s.repository.FindDue(ctx, now, schedulerBatchSize)
+	if err != nil {
+		return
+	}
+
+	for _, schedule := range schedules {
+		select {
+		case <-s.stopChan:
+			return
+		default:
+		}
+		s.run(ctx, schedule, now)
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, schedule *domain.Schedule, now time.Time) {
+	due := *schedule.NextRunAt
+	next, err := NextScheduleRun(schedule, now)
+	if err != nil {
+		// The expression was valid when stored; stop a schedule that no
+		// longer parses rather than retrying it every poll
+		schedule.NextRunAt = nil
+		schedule.LastError = err.Error()
+		schedule.Paused = true
+		s.repository.Update(ctx, schedule)
+		return
+	}
+
+	schedule.NextRunAt = &next
+	schedule.LastRunAt = &due
+	if err := s.repository.Advance(ctx, schedule, due); err != nil {
+		// Another instance claimed the run, or the schedule was changed
+		return
+	}
+
+	task := schedule.Task.NewTask(schedule.UserID)
+	if err := s.taskService.SubmitTask(ctx, task); err != nil {
+		var taskID *uint
+		if task.ID != 0 {
+			taskID = &task.ID
+		}
+		s.repository.RecordRun(ctx, schedule.ID, taskID, err.Error())
+		return
+	}
+	s.repository.RecordRun(ctx, schedule.ID, &task.ID, "")
+}
+
+// Shutdown stops the scheduler and waits for the current poll to finish
+func (s *Scheduler) Shutdown() {
+	close(s.stopChan)
+	s.wg.Wait()
+}
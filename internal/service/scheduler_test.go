@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golangwithgin/internal/domain"
+	"golangwithgin/internal/domain/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestScheduler returns a scheduler that does not poll so tests drive
+// runs directly
+func newTestScheduler(repository domain.ScheduleRepository, taskService domain.TaskService) *Scheduler {
+	return &Scheduler{
+		repository:  repository,
+		taskService: taskService,
+		stopChan:    make(chan struct{}),
+	}
+}
+
+func TestScheduler_RunsDueSchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repository := mocks.NewMockScheduleRepository(ctrl)
+	taskService := mocks.NewMockTaskService(ctrl)
+
+	now := time.Date(2025, 1, 15, 12, 0, 30, 0, time.UTC)
+	// The server was down over several runs; only one task is submitted
+	due := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	schedule := &domain.Schedule{
+		ID:             3,
+		UserID:         7,
+		CronExpression: "@hourly",
+		Timezone:       "UTC",
+		Task:           domain.TaskTemplate{Title: "Report", Type: DefaultTaskType, Priority: 2},
+		NextRunAt:      &due,
+	}
+
+	repository.EXPECT().FindDue(gomock.Any(), now, schedulerBatchSize).Return([]*domain.Schedule{schedule}, nil)
+	gomock.InOrder(
+		repository.EXPECT().
+			Advance(gomock.Any(), schedule, due).
+			DoAndReturn(func(_ context.Context, s *domain.Schedule, _ time.Time) error {
+				assert.Equal(t, time.Date(2025, 1, 15, 13, 0, 0, 0, time.UTC), *s.NextRunAt)
+				assert.Equal(t, due, *s.LastRunAt)
+				return nil
+			}),
+		taskService.EXPECT().
+			SubmitTask(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, task *domain.Task) error {
+				assert.Equal(t, uint(7), task.UserID)
+				assert.Equal(t, "Report", task.Title)
+				assert.Equal(t, 2, task.Priority)
+				task.ID = 42
+				return nil
+			}),
+		repository.EXPECT().
+			RecordRun(gomock.Any(), uint(3), gomock.Any(), "").
+			Do(func(_ context.Context, _ uint, taskID *uint, _ string) {
+				assert.Equal(t, uint(42), *taskID)
+			}),
+	)
+
+	newTestScheduler(repository, taskService).runDue(context.Background(), now)
+}
+
+func TestScheduler_SkipsRunClaimedElsewhere(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repository := mocks.NewMockScheduleRepository(ctrl)
+	taskService := mocks.NewMockTaskService(ctrl)
+
+	now := time.Now()
+	due := now.Add(-time.Second)
+	schedule := &domain.Schedule{ID: 3, CronExpression: "@hourly", Timezone: "UTC", NextRunAt: &due}
+
+	repository.EXPECT().FindDue(gomock.Any(), now, schedulerBatchSize).Return([]*domain.Schedule{schedule}, nil)
+	repository.EXPECT().Advance(gomock.Any(), schedule, due).Return(domain.ErrScheduleNotDue)
+
+	newTestScheduler(repository, taskService).runDue(context.Background(), now)
+}
+
+func TestScheduler_RecordsSubmitFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repository := mocks.NewMockScheduleRepository(ctrl)
+	taskService := mocks.NewMockTaskService(ctrl)
+
+	now := time.Now()
+	due := now.Add(-time.Second)
+	schedule := &domain.Schedule{ID: 3, CronExpression: "@hourly", Timezone: "UTC", NextRunAt: &due}
+
+	repository.EXPECT().FindDue(gomock.Any(), now, schedulerBatchSize).Return([]*domain.Schedule{schedule}, nil)
+	repository.EXPECT().Advance(gomock.Any(), schedule, due).Return(nil)
+	taskService.EXPECT().SubmitTask(gomock.Any(), gomock.Any()).Return(errors.New("database is down"))
+	repository.EXPECT().RecordRun(gomock.Any(), uint(3), nil, "database is down")
+
+	newTestScheduler(repository, taskService).runDue(context.Background(), now)
+}
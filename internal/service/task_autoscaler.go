@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"expvar"
+	"golangwithgin/internal/domain"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultScaleInterval is how often the worker pool is resized when the
+	// configuration does not say
+	DefaultScaleInterval = 5 * time.Second
+	// DefaultScaleUpWait is how long a ready task may wait unclaimed before
+	// workers are added, when the configuration does not say
+	DefaultScaleUpWait = 5 * time.Second
+)
+
+// processorMetrics publishes the metrics of each worker pool with expvar,
+// keyed by queue name
+var processorMetrics = expvar.NewMap("task_processor")
+
+// poolMetrics describe one worker pool
+type poolMetrics struct {
+	workers    expvar.Int
+	busy       expvar.Int
+	ready      expvar.Int
+	oldestWait expvar.Float
+	scaleUps   expvar.Int
+	scaleDowns expvar.Int
+}
+
+func newPoolMetrics(queue string) *poolMetrics {
+	metrics := &poolMetrics{}
+	vars := new(expvar.Map).Init()
+	vars.Set("workers", &metrics.workers)
+	vars.Set("busy_workers", &metrics.busy)
+	vars.Set("queue_ready", &metrics.ready)
+	vars.Set("queue_oldest_wait_seconds", &metrics.oldestWait)
+	vars.Set("scale_ups", &metrics.scaleUps)
+	vars.Set("scale_downs", &metrics.scaleDowns)
+	processorMetrics.Set(queue, vars)
+	return metrics
+}
+
+// autoscale resizes the worker pools every scale interval until shutdown
+func (p *TaskProcessor) autoscale() {
+	defer p.wg.Done()
+
+	ctx := context.Background()
+	ticker := time.NewTicker(p.scaleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, pool := range p.pools {
+				p.scale(ctx, pool)
+			}
+		case <-p.stopChan:
+			return
+		}
+	}
+}
+
+// scale compares a pool with its queue's backlog, then adds workers or asks
+// idle ones to retire
+func (p *TaskProcessor) scale(ctx context.Context, pool *workerPool) {
+	// Tasks held by a stopped queue are no reason to add workers
+	if p.state(pool) != domain.QueueStateRunning {
+		return
+	}
+
+	shares, err := p.queue.Shares(ctx, pool.name)
+	if err != nil {
+		p.logger.WithError(err).WithField("queue", pool.name).Warn("Failed to read the task queue backlog")
+		return
+	}
+	// Tasks of users at their concurrency limit would only keep extra
+	// workers idle
+	backlog := claimableBacklog(shares, pool.config.UserConcurrency, time.Now())
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	current := pool.workers - pool.retiring
+	busy := pool.busy
+	desired := p.desiredWorkers(pool, current, busy, backlog)
+
+	fields := logrus.Fields{
+		"queue":       pool.name,
+		"from":        current,
+		"to":          desired,
+		"busy":        busy,
+		"ready":       backlog.Ready,
+		"oldest_wait": backlog.OldestWait.Round(time.Millisecond).String(),
+	}
+	switch {
+	case desired > current:
+		p.addWorkers(pool, desired-current)
+		pool.metrics.scaleUps.Add(1)
+		p.logger.WithFields(fields).Info("Scaling task workers up")
+	case desired < current:
+		// Tokens are taken by workers between tasks, so running tasks are
+		// never interrupted. The send must not block while p.mu is held, as
+		// exiting workers need the lock; a full buffer skips the token.
+		for i := desired; i < current; i++ {
+			select {
+			case pool.retire <- struct{}{}:
+				pool.retiring++
+			default:
+			}
+		}
+		pool.metrics.scaleDowns.Add(1)
+		p.logger.WithFields(fields).Info("Scaling task workers down")
+	}
+
+	pool.metrics.workers.Set(int64(desired))
+	pool.metrics.busy.Set(int64(busy))
+	pool.metrics.ready.Set(backlog.Ready)
+	pool.metrics.oldestWait.Set(backlog.OldestWait.Seconds())
+}
+
+// desiredWorkers returns how many workers the pool should have. Once ready
+// tasks have waited past the scale up wait, there should be a worker for
+// each of them on top of the busy ones. With nothing ready, one idle worker
+// is retired per interval so that the pool shrinks gradually.
+func (p *TaskProcessor) desiredWorkers(pool *workerPool, current, busy int, backlog *domain.QueueBacklog) int {
+	minWorkers, maxWorkers := pool.config.MinWorkers, pool.config.MaxWorkers
+
+	desired := current
+	switch {
+	case backlog.Ready > 0 && backlog.OldestWait >= p.scaleUpWait:
+		desired = max(current, busy+int(min(backlog.Ready, int64(maxWorkers))))
+	case backlog.Ready == 0 && busy < current:
+		desired = current - 1
+	}
+	return min(max(desired, minWorkers), maxWorkers)
+}
+
+// addWorkers starts n more workers in the pool; the caller holds p.mu
+func (p *TaskProcessor) addWorkers(pool *workerPool, n int) {
+	for i := 0; i < n; i++ {
+		p.workerID++
+		pool.workers++
+		p.wg.Add(1)
+		go p.worker(pool, p.workerID)
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"golangwithgin/internal/domain"
+	"golangwithgin/internal/domain/mocks"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDesiredWorkers(t *testing.T) {
+	p := &TaskProcessor{scaleUpWait: 5 * time.Second}
+	pool := &workerPool{config: QueueConfig{MinWorkers: 2, MaxWorkers: 10}}
+
+	tests := []struct {
+		name          string
+		current, busy int
+		backlog       domain.QueueBacklog
+		want          int
+	}{
+		{"steady", 4, 4, domain.QueueBacklog{}, 4},
+		{"recent backlog is left to idle workers", 4, 2, domain.QueueBacklog{Ready: 3, OldestWait: time.Second}, 4},
+		{"waiting tasks add workers", 4, 4, domain.QueueBacklog{Ready: 3, OldestWait: 10 * time.Second}, 7},
+		{"growth stops at max", 4, 4, domain.QueueBacklog{Ready: 50, OldestWait: time.Minute}, 10},
+		{"idle workers retire one at a time", 6, 1, domain.QueueBacklog{}, 5},
+		{"shrinking stops at min", 2, 0, domain.QueueBacklog{}, 2},
+		{"below min grows to min", 1, 0, domain.QueueBacklog{}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, p.desiredWorkers(pool, tt.current, tt.busy, &tt.backlog))
+		})
+	}
+}
+
+func TestAutoscale_GrowsAndRetiresWorkers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	queue := mocks.NewMockTaskQueue(ctrl)
+	states := mocks.NewMockQueueStateRepository(ctrl)
+	storeQueueStates(states)
+	results := mocks.NewMockTaskResultRepository(ctrl)
+	results.EXPECT().DeleteExpired(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+	queue.EXPECT().Claim(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, domain.ErrQueueEmpty).AnyTimes()
+
+	// Tasks wait in the reports queue until the backlog is drained, while
+	// the default queue stays empty
+	var drained atomic.Bool
+	queue.EXPECT().
+		Shares(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, name string) ([]domain.UserShare, error) {
+			if name != "reports" || drained.Load() {
+				return nil, nil
+			}
+			oldest := time.Now().Add(-time.Minute)
+			return []domain.UserShare{{UserID: 1, Ready: 10, OldestReady: &oldest}}, nil
+		}).
+		AnyTimes()
+
+	processor := NewTaskProcessor(nil, queue, states, results, NewHandlerRegistry(), nil, ProcessorConfig{
+		MinWorkers: 1,
+		MaxWorkers: 2,
+		Queues: map[string]QueueConfig{
+			"reports": {MinWorkers: 1, MaxWorkers: 3},
+		},
+		ScaleInterval: 10 * time.Millisecond,
+		ScaleUpWait:   time.Second,
+	}).(*TaskProcessor)
+	defer processor.Shutdown(context.Background())
+
+	workers := func(name string) int {
+		processor.mu.Lock()
+		defer processor.mu.Unlock()
+		return processor.pools[name].workers
+	}
+
+	assert.Eventually(t, func() bool { return workers("reports") == 3 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 1, workers(domain.DefaultQueue))
+
+	drained.Store(true)
+	assert.Eventually(t, func() bool { return workers("reports") == 1 }, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"golangwithgin/internal/domain"
+	"sync"
+	"time"
+)
+
+const eventPurgeInterval = time.Hour
+
+// DefaultEventRetention is how long task events are kept when no retention
+// is configured
+const DefaultEventRetention = 24 * time.Hour
+
+// taskEventBroker implements the TaskEventBroker interface. Events are stored
+// in the repository so clients can resume after reconnecting; subscribers on
+// this server instance are woken up as soon as an event is published.
+type taskEventBroker struct {
+	repository domain.TaskEventRepository
+	retention  time.Duration
+
+	mu          sync.Mutex
+	subscribers map[uint]map[chan struct{}]struct{}
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewTaskEventBroker creates a broker that keeps events for retention and
+// purges older ones in the background until it is closed
+func NewTaskEventBroker(repository domain.TaskEventRepository, retention time.Duration) domain.TaskEventBroker {
+	if retention <= 0 {
+		retention = DefaultEventRetention
+	}
+
+	broker := &taskEventBroker{
+		repository:  repository,
+		retention:   retention,
+		subscribers: make(map[uint]map[chan struct{}]struct{}),
+		done:        make(chan struct{}),
+	}
+
+	broker.wg.Add(1)
+	go broker.purge()
+	return broker
+}
+
+// Publish records the task's current status. Publishing is best effort: a
+// failure to store the event never fails the status change itself. Events
+// are stored even if the request that changed the task has gone away.
+func (b *taskEventBroker) Publish(task *domain.Task) {
+	event := &domain.TaskEvent{
+		TaskID:    task.ID,
+		UserID:    task.UserID,
+		Type:      domain.TaskEventStatus,
+		Status:    task.Status,
+		CreatedAt: time.Now(),
+	}
+	switch task.Status {
+	case domain.TaskStatusPending, domain.TaskStatusFailed, domain.TaskStatusDead, domain.TaskStatusTimedOut:
+		// Explain why a task is being retried or has given up
+		event.Message = task.LastError
+	}
+
+	if err := b.repository.Append(context.Background(), event); err != nil {
+		return
+	}
+	b.notify(task.UserID)
+}
+
+// PublishProgress records the progress last reported for a running task
+func (b *taskEventBroker) PublishProgress(task *domain.Task) {
+	progress := task.Progress
+	event := &domain.TaskEvent{
+		TaskID:    task.ID,
+		UserID:    task.UserID,
+		Type:      domain.TaskEventProgress,
+		Status:    task.Status,
+		Message:   task.ProgressMessage,
+		Progress:  &progress,
+		CreatedAt: time.Now(),
+	}
+
+	if err := b.repository.Append(context.Background(), event); err != nil {
+		return
+	}
+	b.notify(task.UserID)
+}
+
+func (b *taskEventBroker) List(ctx context.Context, filter domain.TaskEventFilter) ([]*domain.TaskEvent, error) {
+	return b.repository.List(ctx, filter)
+}
+
+func (b *taskEventBroker) LastID(ctx context.Context, userID uint) (uint, error) {
+	return b.repository.LastID(ctx, userID)
+}
+
+func (b *taskEventBroker) Subscribe(userID uint) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	b.mu.Lock()
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan struct{}]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.subscribers[userID], ch)
+		if len(b.subscribers[userID]) == 0 {
+			delete(b.subscribers, userID)
+		}
+	}
+}
+
+func (b *taskEventBroker) notify(userID uint) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[userID] {
+		// A pending signal already tells the subscriber to look again
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (b *taskEventBroker) Done() <-chan struct{} {
+	return b.done
+}
+
+// Close ends all streams and stops purging expired events
+func (b *taskEventBroker) Close() {
+	b.closeOnce.Do(func() {
+		close(b.done)
+	})
+	b.wg.Wait()
+}
+
+// purge deletes events older than the retention period now and then until
+// the broker is closed
+func (b *taskEventBroker) purge() {
+	defer b.wg.Done()
+
+	ctx := context.Background()
+	ticker := time.NewTicker(eventPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		b.repository.DeleteBefore(ctx, time.Now().Add(-b.retention))
+
+		select {
+		case <-ticker.C:
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// publisherGroup fans task events out to several publishers
+type publisherGroup []domain.TaskEventPublisher
+
+// NewPublisherGroup creates a publisher that publishes every event to each
+// of the given publishers in order
+func NewPublisherGroup(publishers ...domain.TaskEventPublisher) domain.TaskEventPublisher {
+	return publisherGroup(publishers)
+}
+
+func (g publisherGroup) Publish(task *domain.Task) {
+	for _, publisher := range g {
+		publisher.Publish(task)
+	}
+}
+
+func (g publisherGroup) PublishProgress(task *domain.Task) {
+	for _, publisher := range g {
+		publisher.PublishProgress(task)
+	}
+}
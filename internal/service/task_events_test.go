@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"golangwithgin/internal/domain"
+	"golangwithgin/internal/domain/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskEventBroker_PublishNotifiesSubscribers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repository := mocks.NewMockTaskEventRepository(ctrl)
+	repository.EXPECT().DeleteBefore(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+	repository.EXPECT().
+		Append(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, event *domain.TaskEvent) error {
+			assert.Equal(t, uint(1), event.TaskID)
+			assert.Equal(t, uint(7), event.UserID)
+			assert.Equal(t, domain.TaskEventStatus, event.Type)
+			assert.Equal(t, domain.TaskStatusDead, event.Status)
+			assert.Equal(t, "boom", event.Message)
+			event.ID = 42
+			return nil
+		})
+
+	broker := NewTaskEventBroker(repository, time.Hour)
+	defer broker.Close()
+
+	notify, unsubscribe := broker.Subscribe(7)
+	other, unsubscribeOther := broker.Subscribe(8)
+	defer unsubscribeOther()
+
+	broker.Publish(&domain.Task{ID: 1, UserID: 7, Status: domain.TaskStatusDead, LastError: "boom"})
+
+	select {
+	case <-notify:
+	default:
+		t.Fatal("subscriber was not notified")
+	}
+	select {
+	case <-other:
+		t.Fatal("another user's subscriber was notified")
+	default:
+	}
+
+	unsubscribe()
+	repository.EXPECT().Append(gomock.Any(), gomock.Any()).Return(nil)
+	broker.Publish(&domain.Task{ID: 1, UserID: 7, Status: domain.TaskStatusPending})
+	select {
+	case <-notify:
+		t.Fatal("unsubscribed channel was notified")
+	default:
+	}
+}
+
+func TestTaskEventBroker_CloseEndsStreams(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repository := mocks.NewMockTaskEventRepository(ctrl)
+	repository.EXPECT().DeleteBefore(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+
+	broker := NewTaskEventBroker(repository, time.Hour)
+	broker.Close()
+
+	select {
+	case <-broker.Done():
+	default:
+		t.Fatal("broker is not done after Close")
+	}
+	// Closing twice is harmless
+	broker.Close()
+}
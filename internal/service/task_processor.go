@@ -1,83 +1,808 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"golangwithgin/internal/domain"
+	"io"
+	"os"
+	"sort"
 	"sync"
 	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultMinWorkers and DefaultMaxWorkers bound a worker pool when the
+	// configuration does not
+	DefaultMinWorkers = 2
+	DefaultMaxWorkers = 20
+	// DefaultQueueCapacity is how many waiting tasks a queue holds before
+	// new submissions are refused, when the configuration does not say
+	DefaultQueueCapacity = 1000
 )
 
-// TaskProcessor implements the TaskProcessor interface
+const (
+	taskLeaseDuration   = 30 * time.Second
+	queuePollInterval   = time.Second
+	resultPurgeInterval = time.Hour
+)
+
+// errShutdown stops the tasks still running when shutdown runs out of time
+var errShutdown = errors.New("task processor shut down")
+
+// DefaultTaskTimeout is how long an attempt may run when neither the task
+// nor the configuration sets a timeout
+const DefaultTaskTimeout = 10 * time.Minute
+
+// ProcessorConfig holds the policies a TaskProcessor applies to the tasks it
+// runs
+type ProcessorConfig struct {
+	Retry   RetryPolicy
+	Results ResultPolicy
+	// Timeout limits attempts of tasks that do not set their own timeout
+	Timeout time.Duration
+	// ProgressInterval is the minimum time between two progress writes of
+	// a task
+	ProgressInterval time.Duration
+	// MinWorkers, MaxWorkers, QueueCapacity and UserConcurrency set up the
+	// default queue
+	MinWorkers      int
+	MaxWorkers      int
+	QueueCapacity   int
+	UserConcurrency int
+	// Queues declares named queues, each run by a worker pool of its own.
+	// An entry for the default queue replaces the settings above.
+	Queues map[string]QueueConfig
+	// Every ScaleInterval each pool is resized between its bounds. Workers
+	// are added once a task has been ready for ScaleUpWait without being
+	// claimed.
+	ScaleInterval time.Duration
+	ScaleUpWait   time.Duration
+	// Logger receives scaling decisions; nil discards them
+	Logger logrus.FieldLogger
+}
+
+// TaskProcessor implements the TaskProcessor interface on top of a durable
+// queue. Tasks are claimed from the queue with a lease, so work left behind
+// by a stopped server is picked up again once its lease expires. Workers are
+// the only writers of the processing, completed and dead statuses, and of
+// the pending status when a failed attempt is retried.
 type TaskProcessor struct {
-	tasks      chan *domain.Task
-	workers    int
-	wg         sync.WaitGroup
-	stopChan   chan struct{}
-	resultPool *sync.Pool
+	repository       domain.TaskRepository
+	queue            domain.TaskQueue
+	states           domain.QueueStateRepository
+	results          domain.TaskResultRepository
+	registry         *HandlerRegistry
+	events           domain.TaskEventPublisher
+	retryPolicy      RetryPolicy
+	resultPolicy     ResultPolicy
+	timeout          time.Duration
+	progressInterval time.Duration
+	pools            map[string]*workerPool
+	scaleInterval    time.Duration
+	scaleUpWait      time.Duration
+	logger           logrus.FieldLogger
+	owner            string
+	wg               sync.WaitGroup
+	stopChan         chan struct{}
+	// tasksCtx is the parent of every running task, so that a shutdown
+	// that runs out of time can stop them all
+	tasksCtx  context.Context
+	stopTasks context.CancelCauseFunc
+
+	mu       sync.Mutex
+	running  map[uint]context.CancelCauseFunc
+	workerID int
 }
 
-// NewTaskProcessor creates a new task processor with the specified number of workers
-func NewTaskProcessor() domain.TaskProcessor {
-	processor := &TaskProcessor{
-		tasks:    make(chan *domain.Task, 100),
-		workers:  5,
-		stopChan: make(chan struct{}),
-		resultPool: &sync.Pool{
-			New: func() interface{} {
-				s := ""
-				return &s
-			},
+// NewTaskProcessor creates a new task processor backed by the given queue.
+// Tasks are dispatched to the handler registered for their type, and their
+// results are kept in results according to the configured policies. Every
+// status change is published to events. Each queue is run by a worker pool
+// of its own, which follows the queue's state in states.
+func NewTaskProcessor(repository domain.TaskRepository, queue domain.TaskQueue, states domain.QueueStateRepository, results domain.TaskResultRepository, registry *HandlerRegistry, events domain.TaskEventPublisher, config ProcessorConfig) domain.TaskProcessor {
+	hostname, _ := os.Hostname()
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTaskTimeout
+	}
+	if config.ProgressInterval <= 0 {
+		config.ProgressInterval = DefaultProgressInterval
+	}
+	if config.ScaleInterval <= 0 {
+		config.ScaleInterval = DefaultScaleInterval
+	}
+	if config.ScaleUpWait <= 0 {
+		config.ScaleUpWait = DefaultScaleUpWait
+	}
+	if config.Logger == nil {
+		discard := logrus.New()
+		discard.SetOutput(io.Discard)
+		config.Logger = discard
+	}
+
+	queues := map[string]QueueConfig{
+		domain.DefaultQueue: {
+			MinWorkers:      config.MinWorkers,
+			MaxWorkers:      config.MaxWorkers,
+			Capacity:        config.QueueCapacity,
+			UserConcurrency: config.UserConcurrency,
 		},
 	}
+	for name, queue := range config.Queues {
+		queues[name] = queue
+	}
+	pools := make(map[string]*workerPool, len(queues))
+	for name, queue := range queues {
+		pools[name] = newWorkerPool(name, queue.withDefaults())
+	}
+
+	tasksCtx, stopTasks := context.WithCancelCause(context.Background())
+	processor := &TaskProcessor{
+		repository:       repository,
+		queue:            queue,
+		states:           states,
+		results:          results,
+		registry:         registry,
+		events:           events,
+		retryPolicy:      config.Retry.withDefaults(),
+		resultPolicy:     config.Results.withDefaults(),
+		timeout:          config.Timeout,
+		progressInterval: config.ProgressInterval,
+		pools:            pools,
+		scaleInterval:    config.ScaleInterval,
+		scaleUpWait:      config.ScaleUpWait,
+		logger:           config.Logger,
+		owner:            fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		stopChan:         make(chan struct{}),
+		tasksCtx:         tasksCtx,
+		stopTasks:        stopTasks,
+		running:          make(map[uint]context.CancelCauseFunc),
+	}
 
 	processor.start()
 	return processor
 }
 
 func (p *TaskProcessor) start() {
-	for i := 0; i < p.workers; i++ {
-		p.wg.Add(1)
-		go p.worker()
+	// Queues paused or drained before a restart stay that way
+	if err := p.refreshStates(context.Background()); err != nil {
+		p.logger.WithError(err).Warn("Failed to load task queue states")
+	}
+
+	p.mu.Lock()
+	for _, pool := range p.pools {
+		p.addWorkers(pool, pool.config.MinWorkers)
+	}
+	p.mu.Unlock()
+
+	p.wg.Add(3)
+	go p.purgeResults()
+	go p.autoscale()
+	go p.watchStates()
+}
+
+// purgeResults deletes expired task results now and then until shutdown
+func (p *TaskProcessor) purgeResults() {
+	defer p.wg.Done()
+
+	ctx := context.Background()
+	ticker := time.NewTicker(resultPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		p.results.DeleteExpired(ctx, time.Now())
+
+		select {
+		case <-ticker.C:
+		case <-p.stopChan:
+			return
+		}
 	}
 }
 
-func (p *TaskProcessor) worker() {
+// worker claims and runs tasks of its pool's queue until shutdown, or until
+// it takes a retire token while between tasks
+func (p *TaskProcessor) worker(pool *workerPool, id int) {
 	defer p.wg.Done()
 
+	retired := false
+	defer func() {
+		p.mu.Lock()
+		pool.workers--
+		if retired {
+			pool.retiring--
+		}
+		p.mu.Unlock()
+	}()
+
+	// Workers finish their tasks on shutdown, so their queries are not
+	// tied to it
+	ctx := context.Background()
+	owner := fmt.Sprintf("%s/%s-worker-%d", p.owner, pool.name, id)
+	ticker := time.NewTicker(queuePollInterval)
+	defer ticker.Stop()
+
+	// wait blocks until the worker should poll again, and reports whether
+	// it should keep going
+	wait := func(due <-chan time.Time) bool {
+		select {
+		case <-pool.wakeup:
+		case <-due:
+		case <-pool.retire:
+			retired = true
+			return false
+		case <-p.stopChan:
+			return false
+		}
+		return true
+	}
+
 	for {
 		select {
-		case task := <-p.tasks:
-			// Get a result string from the pool
-			result := p.resultPool.Get().(*string)
-
-			// Process the task
-			*result = fmt.Sprintf("Processed task '%s' by worker %d at %v", task.Title, p.workers, time.Now())
-			
-			// Simulate some work
-			time.Sleep(time.Second)
-
-			// Update task status
-			task.Status = "completed"
-			task.UpdatedAt = time.Now()
-
-			// Put the result string back in the pool
-			p.resultPool.Put(result)
 		case <-p.stopChan:
 			return
+		case <-pool.retire:
+			retired = true
+			return
+		default:
+		}
+
+		// Paused and drained queues are not claimed from, so their workers
+		// leave the database alone during maintenance. watchStates reloads
+		// the state from the database on a ticker of its own every second.
+		if !p.acquire(pool) {
+			if !wait(ticker.C) {
+				return
+			}
+			continue
+		}
+
+		// A rate limited queue is only claimed from once a start is due
+		now := time.Now()
+		if delay, ok := pool.reserveStart(now); !ok {
+			p.release(pool)
+			if !wait(time.After(delay)) {
+				return
+			}
+			continue
+		}
+
+		entry, err := p.claim(ctx, pool, owner)
+		if err != nil {
+			pool.releaseStart(now)
+			p.release(pool)
+			// Queue is empty or unreachable, wait before polling again
+			if !wait(ticker.C) {
+				return
+			}
+			continue
 		}
+
+		p.handle(ctx, pool, entry)
+		p.release(pool)
+	}
+}
+
+// claim leases the next task of the pool's queue. Users take turns: the
+// task comes from the user running the fewest tasks, or the one served
+// least recently among equals, and is the one with the highest aged
+// priority among theirs.
+func (p *TaskProcessor) claim(ctx context.Context, pool *workerPool, owner string) (*domain.QueuedTask, error) {
+	shares, err := p.queue.Shares(ctx, pool.name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, userID := range fairOrder(shares, pool.config.UserConcurrency, pool.lastServed(shares)) {
+		entry, err := p.queue.Claim(ctx, pool.name, userID, owner, taskLeaseDuration)
+		// Other workers may have claimed the user's tasks in the meantime
+		if errors.Is(err, domain.ErrQueueEmpty) {
+			continue
+		}
+		if err == nil {
+			pool.markServed(userID, time.Now())
+		}
+		return entry, err
+	}
+	return nil, domain.ErrQueueEmpty
+}
+
+// acquire counts the worker as active if its pool's queue is running, and
+// reports whether it may claim a task. Workers that acquired call release
+// once they are done with the claim and its task.
+func (p *TaskProcessor) acquire(pool *workerPool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pool.state != domain.QueueStateRunning {
+		return false
+	}
+	pool.active++
+	return true
+}
+
+func (p *TaskProcessor) release(pool *workerPool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pool.active--
+	p.settle(pool)
+}
+
+// settle marks a draining pool drained once none of its workers are
+// active; the caller holds p.mu
+func (p *TaskProcessor) settle(pool *workerPool) {
+	if pool.state == domain.QueueStateDraining && pool.active == 0 {
+		pool.state = domain.QueueStateDrained
+		p.logger.WithField("queue", pool.name).Info("Task queue drained")
+	}
+}
+
+// handle runs a claimed task while keeping its lease alive. The handler is
+// stopped when the task is cancelled, its lease is lost, it runs past its
+// timeout or shutdown runs out of time.
+func (p *TaskProcessor) handle(ctx context.Context, pool *workerPool, entry *domain.QueuedTask) {
+	task, err := p.repository.FindByID(ctx, entry.TaskID)
+	if errors.Is(err, domain.ErrTaskNotFound) {
+		// The task was deleted, so nothing is left to run
+		p.queue.Complete(ctx, entry)
+		return
+	}
+	if err != nil {
+		// Leave the entry leased; it becomes claimable again once the lease expires
+		return
+	}
+
+	runCtx, cancel := context.WithCancelCause(p.tasksCtx)
+	defer cancel(nil)
+
+	p.mu.Lock()
+	p.running[task.ID] = cancel
+	pool.busy++
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.running, task.ID)
+		pool.busy--
+		p.mu.Unlock()
+	}()
+
+	// The task may have been cancelled between the claim and registering it
+	// as running, in which case its queue entry is already gone
+	if err := p.queue.ExtendLease(ctx, entry, taskLeaseDuration); errors.Is(err, domain.ErrLeaseLost) {
+		return
+	}
+
+	// Every attempt starts its progress over
+	task.Attempts++
+	task.Progress = 0
+	task.ProgressMessage = ""
+	if !p.transition(ctx, entry, task, domain.TaskStatusProcessing) {
+		return
+	}
+
+	timeout := p.taskTimeout(task)
+	runCtx, stop := context.WithTimeoutCause(runCtx, timeout, domain.ErrTaskTimedOut)
+	defer stop()
+
+	progress := newProgressReporter(p.repository, p.events, p.progressInterval, task)
+	done := make(chan struct{})
+	go p.keepAlive(ctx, entry, cancel, done)
+	result, err := p.execute(progress.withProgress(runCtx), task)
+	close(done)
+	task.Progress, task.ProgressMessage = progress.close()
+
+	switch cause := context.Cause(runCtx); {
+	case errors.Is(cause, domain.ErrTaskTimedOut) && err != nil:
+		task.LastError = fmt.Sprintf("timed out after %s", timeout)
+		if p.transition(ctx, entry, task, domain.TaskStatusTimedOut) {
+			p.queue.Complete(ctx, entry)
+		}
+		return
+	case cause != nil && !errors.Is(cause, domain.ErrTaskTimedOut):
+		// Cancelled tasks are finalised by whoever cancelled them, a lost
+		// lease means another worker now owns the task, and tasks stopped by
+		// shutdown are claimed again once their lease expires
+		return
+	}
+
+	if err == nil && result != nil {
+		err = p.saveResult(ctx, task, result)
+	}
+	// Another attempt would only produce a result as large, so the task
+	// fails without being retried
+	if errors.Is(err, domain.ErrResultTooLarge) {
+		task.LastError = err.Error()
+		if p.transition(ctx, entry, task, domain.TaskStatusFailed) {
+			p.queue.Complete(ctx, entry)
+		}
+		return
+	}
+	if err != nil {
+		p.retryOrBury(ctx, entry, task, err)
+		return
+	}
+
+	task.LastError = ""
+	task.Progress = 100
+	if p.transition(ctx, entry, task, domain.TaskStatusCompleted) {
+		p.queue.Complete(ctx, entry)
 	}
 }
 
-func (p *TaskProcessor) Process(task *domain.Task) error {
-	task.Status = "processing"
+// retryOrBury schedules another attempt of a failed task after a backoff, or
+// moves it to the dead status once it has used up its attempts
+func (p *TaskProcessor) retryOrBury(ctx context.Context, entry *domain.QueuedTask, task *domain.Task, cause error) {
+	task.LastError = cause.Error()
+
+	maxAttempts := task.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = p.retryPolicy.MaxAttempts
+	}
+
+	if task.Attempts < maxAttempts {
+		if p.transition(ctx, entry, task, domain.TaskStatusPending) {
+			p.queue.Retry(ctx, entry, time.Now().Add(p.retryPolicy.Backoff(task.Attempts)))
+		}
+		return
+	}
+
+	if p.transition(ctx, entry, task, domain.TaskStatusDead) {
+		p.queue.Complete(ctx, entry)
+	}
+}
+
+// transition moves a task to the given status. Workers own the attempt
+// count, last error and progress of the tasks they run, so those are saved
+// along with it. If the state machine rejects the move, for example because
+// the task was cancelled in the meantime, the queue entry is dropped and
+// false is returned.
+func (p *TaskProcessor) transition(ctx context.Context, entry *domain.QueuedTask, task *domain.Task, status domain.TaskStatus) bool {
+	previous := task.Status
+	task.Status = status
 	task.UpdatedAt = time.Now()
-	p.tasks <- task
+
+	err := p.repository.Update(ctx, task, "attempts", "last_error", "progress", "progress_message")
+	if errors.Is(err, domain.ErrInvalidTaskStatus) || errors.Is(err, domain.ErrTaskNotFound) {
+		task.Status = previous
+		p.queue.Complete(ctx, entry)
+		return false
+	}
+	if err != nil {
+		// The lease expires and the task is retried later
+		return false
+	}
+
+	p.events.Publish(task)
+	return true
+}
+
+// taskTimeout returns how long an attempt of the task may run
+func (p *TaskProcessor) taskTimeout(task *domain.Task) time.Duration {
+	if task.Timeout > 0 {
+		return time.Duration(task.Timeout) * time.Second
+	}
+	return p.timeout
+}
+
+// execute runs the handler registered for the task's type. A panicking
+// handler fails the attempt instead of taking the worker down, and a
+// handler that ignores the end of its context is abandoned so it cannot
+// hold on to the worker.
+func (p *TaskProcessor) execute(ctx context.Context, task *domain.Task) (*domain.TaskResult, error) {
+	handler, ok := p.registry.Lookup(task.Type)
+	if !ok {
+		return nil, fmt.Errorf("no handler registered for task type %q", task.Type)
+	}
+
+	type outcome struct {
+		result *domain.TaskResult
+		err    error
+	}
+	// The handler gets its own copy of the task, as an abandoned handler
+	// may still be reading it while the worker moves on
+	copied := *task
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- outcome{err: fmt.Errorf("handler for task type %q panicked: %v", task.Type, r)}
+			}
+		}()
+		result, err := handler(ctx, &copied)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.result, o.err
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+}
+
+// saveResult stores a handler's result. Results over the size limit fail the
+// task.
+func (p *TaskProcessor) saveResult(ctx context.Context, task *domain.Task, result *domain.TaskResult) error {
+	if err := p.resultPolicy.prepare(task, result, time.Now()); err != nil {
+		return err
+	}
+	return p.results.Save(ctx, result)
+}
+
+// keepAlive extends the lease of an entry until done is closed. If the lease
+// is lost, for example because the task was cancelled, the task is stopped.
+func (p *TaskProcessor) keepAlive(ctx context.Context, entry *domain.QueuedTask, cancel context.CancelCauseFunc, done <-chan struct{}) {
+	ticker := time.NewTicker(taskLeaseDuration / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.queue.ExtendLease(ctx, entry, taskLeaseDuration); errors.Is(err, domain.ErrLeaseLost) {
+				cancel(domain.ErrLeaseLost)
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// pool returns the worker pool of the named queue
+func (p *TaskProcessor) pool(queue string) (*workerPool, bool) {
+	if queue == "" {
+		queue = domain.DefaultQueue
+	}
+	pool, ok := p.pools[queue]
+	return pool, ok
+}
+
+// Admit refuses tasks whose queue is draining or lacks room for them among
+// its ready tasks. The check is not atomic, so a queue may overshoot slightly.
+func (p *TaskProcessor) Admit(ctx context.Context, tasks []*domain.Task) error {
+	counts := make(map[*workerPool]int64)
+	for _, task := range tasks {
+		pool, ok := p.pool(task.Queue)
+		if !ok {
+			return fmt.Errorf("%w: unknown queue %q", domain.ErrInvalidTask, task.Queue)
+		}
+		// Blocked tasks take no room until their dependencies finish
+		count := counts[pool]
+		if task.Status != domain.TaskStatusBlocked {
+			count++
+		}
+		counts[pool] = count
+	}
+
+	// Another server may have drained a queue since the last refresh
+	if err := p.refreshStates(ctx); err != nil {
+		return err
+	}
+	for pool := range counts {
+		if state := p.state(pool); !state.AcceptsTasks() {
+			return fmt.Errorf("%w: %s is %s", domain.ErrQueueDraining, pool.name, state)
+		}
+	}
+	for pool, count := range counts {
+		if count == 0 {
+			continue
+		}
+		depth, err := p.queue.Depth(ctx, pool.name)
+		if err != nil {
+			return err
+		}
+		if depth+count > int64(pool.config.Capacity) {
+			return fmt.Errorf("%w: %s", domain.ErrQueueFull, pool.name)
+		}
+	}
+	return nil
+}
+
+// Process durably enqueues the task; a worker of its queue picks it up
+// asynchronously
+func (p *TaskProcessor) Process(ctx context.Context, task *domain.Task) error {
+	if err := p.queue.Enqueue(ctx, task); err != nil {
+		return err
+	}
+
+	if pool, ok := p.pool(task.Queue); ok {
+		pool.wake()
+	}
+	return nil
+}
+
+// Queues reports every configured queue in name order. Depths and
+// in-flight counts cover all servers; worker counts only this one.
+func (p *TaskProcessor) Queues(ctx context.Context) ([]*domain.QueueStats, error) {
+	counts, err := p.queue.Counts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	queues := make([]*domain.QueueStats, 0, len(p.pools))
+	for name, pool := range p.pools {
+		queues = append(queues, &domain.QueueStats{
+			Name:            name,
+			State:           pool.state,
+			Depth:           counts[name].Depth,
+			InFlight:        counts[name].InFlight,
+			Workers:         pool.workers - pool.retiring,
+			Busy:            pool.busy,
+			MinWorkers:      pool.config.MinWorkers,
+			Concurrency:     pool.config.MaxWorkers,
+			Capacity:        pool.config.Capacity,
+			RateLimit:       pool.config.RateLimit,
+			UserConcurrency: pool.config.UserConcurrency,
+		})
+	}
+	sort.Slice(queues, func(i, j int) bool { return queues[i].Name < queues[j].Name })
+	return queues, nil
+}
+
+// Pause stops every server from starting the queue's tasks. Running tasks
+// finish, and new ones are accepted and held until the queue is resumed.
+func (p *TaskProcessor) Pause(ctx context.Context, queue string) (domain.QueueState, error) {
+	return p.setState(ctx, queue, domain.QueueStatePaused)
+}
+
+// Resume lets every server start the queue's tasks again, held ones included
+func (p *TaskProcessor) Resume(ctx context.Context, queue string) (domain.QueueState, error) {
+	return p.setState(ctx, queue, domain.QueueStateRunning)
+}
+
+// Drain stops every server from starting the queue's tasks and refuses new
+// ones. The queue is drained on a server once the tasks it was running
+// there have finished.
+func (p *TaskProcessor) Drain(ctx context.Context, queue string) (domain.QueueState, error) {
+	return p.setState(ctx, queue, domain.QueueStateDraining)
+}
+
+// setState stores the queue's new state for every server to follow, and
+// follows it on this one right away
+func (p *TaskProcessor) setState(ctx context.Context, queue string, state domain.QueueState) (domain.QueueState, error) {
+	pool, ok := p.pool(queue)
+	if !ok {
+		return "", domain.ErrQueueNotFound
+	}
+	if err := p.states.Set(ctx, pool.name, state); err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.follow(pool, state)
+	return pool.state, nil
+}
+
+// watchStates refreshes the state of every queue from the database now and
+// then until shutdown, so that changes made through other servers apply here
+func (p *TaskProcessor) watchStates() {
+	defer p.wg.Done()
+
+	ctx := context.Background()
+	ticker := time.NewTicker(queuePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// An unreachable database keeps the last known states
+			p.refreshStates(ctx)
+		case <-p.stopChan:
+			return
+		}
+	}
+}
+
+// refreshStates makes every pool follow the state stored for its queue
+func (p *TaskProcessor) refreshStates(ctx context.Context) error {
+	states, err := p.states.All(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for name, pool := range p.pools {
+		state, ok := states[name]
+		if !ok {
+			state = domain.QueueStateRunning
+		}
+		p.follow(pool, state)
+	}
+	return nil
+}
+
+// follow moves the pool to the stored state of its queue; the caller holds
+// p.mu
+func (p *TaskProcessor) follow(pool *workerPool, state domain.QueueState) {
+	// A queue stays drained on this server until it is changed again
+	if state == domain.QueueStateDraining && pool.state == domain.QueueStateDrained {
+		return
+	}
+	if pool.state == state {
+		return
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"queue": pool.name,
+		"from":  pool.state,
+		"to":    state,
+	}).Info("Changing task queue state")
+	pool.state = state
+	p.settle(pool)
+	if state == domain.QueueStateRunning {
+		pool.wake()
+	}
+}
+
+// States returns the state of every configured queue on this server
+func (p *TaskProcessor) States() map[string]domain.QueueState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	states := make(map[string]domain.QueueState, len(p.pools))
+	for name, pool := range p.pools {
+		states[name] = pool.state
+	}
+	return states
+}
+
+// state returns the state of the pool's queue
+func (p *TaskProcessor) state(pool *workerPool) domain.QueueState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return pool.state
+}
+
+// Cancel removes a task from the queue and stops it if it is running on
+// this processor. Tasks running on another server instance stop once their
+// worker notices that the queue entry is gone.
+func (p *TaskProcessor) Cancel(ctx context.Context, taskID uint) error {
+	if err := p.queue.Remove(ctx, taskID); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	cancel, running := p.running[taskID]
+	p.mu.Unlock()
+
+	if running {
+		cancel(domain.ErrTaskCancelled)
+	}
 	return nil
 }
 
-// Shutdown gracefully shuts down the processor
-func (p *TaskProcessor) Shutdown() {
+// Shutdown stops claiming new tasks and waits for in-flight tasks to finish
+// and record their final status. If ctx ends first, the tasks still running
+// are stopped and their entries left for the lease to expire, so that they
+// run again on another server or after a restart, and ctx's error is
+// returned. Tasks that have not started stay queued and are resumed after a
+// restart.
+func (p *TaskProcessor) Shutdown(ctx context.Context) error {
 	close(p.stopChan)
-	p.wg.Wait()
-	close(p.tasks)
-} 
\ No newline at end of file
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		p.stopTasks(errShutdown)
+		<-done
+		return ctx.Err()
+	}
+}
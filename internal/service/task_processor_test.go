@@ -0,0 +1,631 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golangwithgin/internal/domain"
+	"golangwithgin/internal/domain/mocks"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+)
+
+type TaskProcessorTestSuite struct {
+	suite.Suite
+	mockCtrl       *gomock.Controller
+	mockRepository *mocks.MockTaskRepository
+	mockQueue      *mocks.MockTaskQueue
+	mockStates     *mocks.MockQueueStateRepository
+	mockResults    *mocks.MockTaskResultRepository
+	mockEvents     *mocks.MockTaskEventPublisher
+	// storeState changes a stored queue state the way another server would
+	storeState func(queue string, state domain.QueueState)
+}
+
+func TestTaskProcessorSuite(t *testing.T) {
+	suite.Run(t, new(TaskProcessorTestSuite))
+}
+
+func (s *TaskProcessorTestSuite) SetupTest() {
+	s.mockCtrl = gomock.NewController(s.T())
+	s.mockRepository = mocks.NewMockTaskRepository(s.mockCtrl)
+	s.mockQueue = mocks.NewMockTaskQueue(s.mockCtrl)
+	s.mockStates = mocks.NewMockQueueStateRepository(s.mockCtrl)
+	s.storeState = storeQueueStates(s.mockStates)
+	s.mockResults = mocks.NewMockTaskResultRepository(s.mockCtrl)
+	s.mockResults.EXPECT().DeleteExpired(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+	s.mockEvents = mocks.NewMockTaskEventPublisher(s.mockCtrl)
+	s.mockEvents.EXPECT().Publish(gomock.Any()).AnyTimes()
+	// A user with a task that only just became ready lets workers claim,
+	// and keeps the autoscaler from resizing the pool
+	s.mockQueue.EXPECT().Shares(gomock.Any(), gomock.Any()).Return([]domain.UserShare{{UserID: 1, Ready: 1}}, nil).AnyTimes()
+}
+
+func (s *TaskProcessorTestSuite) TearDownTest() {
+	s.mockCtrl.Finish()
+}
+
+// newProcessor starts a processor that runs tasks of type "test" with handler
+func (s *TaskProcessorTestSuite) newProcessor(handler TaskHandlerFunc) domain.TaskProcessor {
+	registry := NewHandlerRegistry()
+	registry.Register("test", handler)
+	return NewTaskProcessor(s.mockRepository, s.mockQueue, s.mockStates, s.mockResults, registry, s.mockEvents, ProcessorConfig{
+		Retry:   RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Minute},
+		Results: ResultPolicy{MaxSize: 16, Retention: time.Hour},
+	})
+}
+
+func succeed(context.Context, *domain.Task) (*domain.TaskResult, error) { return nil, nil }
+
+// storeQueueStates makes states keep queue states in memory the way the
+// database would, and returns a function that stores one directly
+func storeQueueStates(states *mocks.MockQueueStateRepository) func(string, domain.QueueState) {
+	var mu sync.Mutex
+	stored := make(map[string]domain.QueueState)
+	store := func(queue string, state domain.QueueState) {
+		mu.Lock()
+		defer mu.Unlock()
+		stored[queue] = state
+	}
+
+	states.EXPECT().
+		Set(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, queue string, state domain.QueueState) error {
+			store(queue, state)
+			return nil
+		}).
+		AnyTimes()
+	states.EXPECT().
+		All(gomock.Any()).
+		DoAndReturn(func(context.Context) (map[string]domain.QueueState, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			copied := make(map[string]domain.QueueState, len(stored))
+			for queue, state := range stored {
+				copied[queue] = state
+			}
+			return copied, nil
+		}).
+		AnyTimes()
+	return store
+}
+
+// expectSingleClaim makes the queue hand out entry once and then report empty
+func (s *TaskProcessorTestSuite) expectSingleClaim(entry *domain.QueuedTask) {
+	var claimed int32
+	s.mockQueue.EXPECT().
+		Claim(gomock.Any(), domain.DefaultQueue, gomock.Any(), gomock.Any(), taskLeaseDuration).
+		DoAndReturn(func(_ context.Context, _ string, _ uint, owner string, lease time.Duration) (*domain.QueuedTask, error) {
+			if !atomic.CompareAndSwapInt32(&claimed, 0, 1) {
+				return nil, domain.ErrQueueEmpty
+			}
+			entry.LeaseOwner = owner
+			return entry, nil
+		}).
+		AnyTimes()
+}
+
+func (s *TaskProcessorTestSuite) TestProcess_CompletesQueuedTask() {
+	task := &domain.Task{ID: 1, Title: "Test Task", Type: "test", Status: domain.TaskStatusPending}
+	entry := &domain.QueuedTask{ID: 10, TaskID: task.ID}
+	finished := make(chan struct{})
+
+	s.mockQueue.EXPECT().Enqueue(gomock.Any(), task).Return(nil)
+	s.expectSingleClaim(entry)
+	s.mockRepository.EXPECT().FindByID(gomock.Any(), task.ID).Return(task, nil)
+	s.mockQueue.EXPECT().ExtendLease(gomock.Any(), entry, taskLeaseDuration).Return(nil)
+	gomock.InOrder(
+		s.mockRepository.EXPECT().
+			Update(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, t *domain.Task, _ ...string) error {
+				s.Equal(domain.TaskStatusProcessing, t.Status)
+				return nil
+			}),
+		s.mockRepository.EXPECT().
+			Update(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, t *domain.Task, _ ...string) error {
+				s.Equal(domain.TaskStatusCompleted, t.Status)
+				return nil
+			}),
+	)
+	s.mockQueue.EXPECT().
+		Complete(gomock.Any(), entry).
+		DoAndReturn(func(context.Context, *domain.QueuedTask) error {
+			close(finished)
+			return nil
+		})
+
+	processor := s.newProcessor(succeed)
+	defer processor.Shutdown(context.Background())
+
+	s.NoError(processor.Process(context.Background(), task))
+	<-finished
+}
+
+func (s *TaskProcessorTestSuite) TestProcess_EnqueueError() {
+	task := &domain.Task{ID: 1, Title: "Test Task"}
+
+	s.mockQueue.EXPECT().Claim(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, domain.ErrQueueEmpty).AnyTimes()
+	expectedErr := errors.New("enqueue error")
+	s.mockQueue.EXPECT().Enqueue(gomock.Any(), task).Return(expectedErr)
+
+	processor := s.newProcessor(succeed)
+	defer processor.Shutdown(context.Background())
+
+	s.Equal(expectedErr, processor.Process(context.Background(), task))
+}
+
+func (s *TaskProcessorTestSuite) TestAdmit_RefusesTasksBeyondCapacity() {
+	s.mockQueue.EXPECT().Claim(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, domain.ErrQueueEmpty).AnyTimes()
+	s.mockQueue.EXPECT().Depth(gomock.Any(), domain.DefaultQueue).Return(int64(2), nil).Times(2)
+
+	processor := NewTaskProcessor(s.mockRepository, s.mockQueue, s.mockStates, s.mockResults, NewHandlerRegistry(), s.mockEvents, ProcessorConfig{
+		MinWorkers:    1,
+		QueueCapacity: 3,
+	})
+	defer processor.Shutdown(context.Background())
+
+	s.NoError(processor.Admit(context.Background(), []*domain.Task{{}}))
+	s.ErrorIs(processor.Admit(context.Background(), []*domain.Task{{}, {}}), domain.ErrQueueFull)
+}
+
+func (s *TaskProcessorTestSuite) TestAdmit_ChecksEachQueueAgainstItsOwnCapacity() {
+	s.mockQueue.EXPECT().Claim(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, domain.ErrQueueEmpty).AnyTimes()
+	s.mockQueue.EXPECT().Depth(gomock.Any(), domain.DefaultQueue).Return(int64(0), nil).AnyTimes()
+	s.mockQueue.EXPECT().Depth(gomock.Any(), "reports").Return(int64(4), nil).AnyTimes()
+
+	processor := NewTaskProcessor(s.mockRepository, s.mockQueue, s.mockStates, s.mockResults, NewHandlerRegistry(), s.mockEvents, ProcessorConfig{
+		MinWorkers: 1,
+		Queues: map[string]QueueConfig{
+			"reports": {MinWorkers: 1, Capacity: 5},
+		},
+	})
+	defer processor.Shutdown(context.Background())
+
+	s.NoError(processor.Admit(context.Background(), []*domain.Task{{Queue: "reports"}, {}}))
+	s.ErrorIs(processor.Admit(context.Background(), []*domain.Task{{Queue: "reports"}, {Queue: "reports"}}), domain.ErrQueueFull)
+	s.ErrorIs(processor.Admit(context.Background(), []*domain.Task{{Queue: "unknown"}}), domain.ErrInvalidTask)
+}
+
+func (s *TaskProcessorTestSuite) TestPause_HoldsTasksUntilResumed() {
+	var claims atomic.Int32
+	s.mockQueue.EXPECT().
+		Claim(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, string, uint, string, time.Duration) (*domain.QueuedTask, error) {
+			claims.Add(1)
+			return nil, domain.ErrQueueEmpty
+		}).
+		AnyTimes()
+	s.mockQueue.EXPECT().Depth(gomock.Any(), domain.DefaultQueue).Return(int64(0), nil)
+	task := &domain.Task{ID: 1, Title: "Test Task", Type: "test"}
+	s.mockQueue.EXPECT().Enqueue(gomock.Any(), task).Return(nil)
+
+	processor := s.newProcessor(succeed)
+	defer processor.Shutdown(context.Background())
+
+	_, err := processor.Pause(context.Background(), "missing")
+	s.ErrorIs(err, domain.ErrQueueNotFound)
+
+	state, err := processor.Pause(context.Background(), domain.DefaultQueue)
+	s.NoError(err)
+	s.Equal(domain.QueueStatePaused, state)
+	s.Equal(domain.QueueStatePaused, processor.States()[domain.DefaultQueue])
+
+	// Let claims that started before the pause finish
+	time.Sleep(20 * time.Millisecond)
+	held := claims.Load()
+
+	// Submissions are still accepted, but nothing is claimed
+	s.NoError(processor.Admit(context.Background(), []*domain.Task{task}))
+	s.NoError(processor.Process(context.Background(), task))
+	time.Sleep(50 * time.Millisecond)
+	s.Equal(held, claims.Load())
+
+	state, err = processor.Resume(context.Background(), domain.DefaultQueue)
+	s.NoError(err)
+	s.Equal(domain.QueueStateRunning, state)
+	s.Eventually(func() bool { return claims.Load() > held }, time.Second, 5*time.Millisecond)
+}
+
+func (s *TaskProcessorTestSuite) TestDrain_FinishesRunningTasksAndRefusesNewOnes() {
+	task := &domain.Task{ID: 1, Title: "Test Task", Type: "test", Status: domain.TaskStatusPending}
+	entry := &domain.QueuedTask{ID: 10, TaskID: task.ID}
+	started := make(chan struct{})
+	finish := make(chan struct{})
+
+	s.expectSingleClaim(entry)
+	s.mockRepository.EXPECT().FindByID(gomock.Any(), task.ID).Return(task, nil)
+	s.mockQueue.EXPECT().ExtendLease(gomock.Any(), entry, taskLeaseDuration).Return(nil)
+	s.mockRepository.EXPECT().Update(gomock.Any(), task, gomock.Any()).Return(nil).Times(2)
+	s.mockQueue.EXPECT().Complete(gomock.Any(), entry).Return(nil)
+
+	processor := s.newProcessor(func(context.Context, *domain.Task) (*domain.TaskResult, error) {
+		close(started)
+		<-finish
+		return nil, nil
+	})
+	defer processor.Shutdown(context.Background())
+
+	<-started
+	state, err := processor.Drain(context.Background(), domain.DefaultQueue)
+	s.NoError(err)
+	s.Equal(domain.QueueStateDraining, state)
+	s.ErrorIs(processor.Admit(context.Background(), []*domain.Task{{}}), domain.ErrQueueDraining)
+
+	close(finish)
+	s.Eventually(func() bool {
+		return processor.States()[domain.DefaultQueue] == domain.QueueStateDrained
+	}, time.Second, 5*time.Millisecond)
+	s.Equal(domain.TaskStatusCompleted, task.Status)
+}
+
+func (s *TaskProcessorTestSuite) TestQueueStates_FollowStatesStoredByOtherServers() {
+	var claims atomic.Int32
+	s.mockQueue.EXPECT().
+		Claim(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, string, uint, string, time.Duration) (*domain.QueuedTask, error) {
+			claims.Add(1)
+			return nil, domain.ErrQueueEmpty
+		}).
+		AnyTimes()
+
+	// A queue paused before the processor starts is never claimed from
+	s.storeState(domain.DefaultQueue, domain.QueueStatePaused)
+	processor := s.newProcessor(succeed)
+	defer processor.Shutdown(context.Background())
+
+	s.Equal(domain.QueueStatePaused, processor.States()[domain.DefaultQueue])
+	time.Sleep(50 * time.Millisecond)
+	s.Zero(claims.Load())
+
+	// Submissions see a drain stored by another server right away
+	s.storeState(domain.DefaultQueue, domain.QueueStateDraining)
+	s.ErrorIs(processor.Admit(context.Background(), []*domain.Task{{}}), domain.ErrQueueDraining)
+	s.Equal(domain.QueueStateDrained, processor.States()[domain.DefaultQueue])
+
+	// Workers pick up a resume on their next poll
+	s.storeState(domain.DefaultQueue, domain.QueueStateRunning)
+	s.Eventually(func() bool { return claims.Load() > 0 }, 3*time.Second, 10*time.Millisecond)
+	s.Equal(domain.QueueStateRunning, processor.States()[domain.DefaultQueue])
+}
+
+func (s *TaskProcessorTestSuite) TestHandle_DropsTaskRejectedByStateMachine() {
+	task := &domain.Task{ID: 1, Title: "Test Task", Type: "test", Status: domain.TaskStatusCancelled}
+	entry := &domain.QueuedTask{ID: 10, TaskID: task.ID}
+	dropped := make(chan struct{})
+
+	s.expectSingleClaim(entry)
+	s.mockRepository.EXPECT().FindByID(gomock.Any(), task.ID).Return(task, nil)
+	s.mockQueue.EXPECT().ExtendLease(gomock.Any(), entry, taskLeaseDuration).Return(nil)
+	s.mockRepository.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(domain.ErrInvalidTaskStatus)
+	s.mockQueue.EXPECT().
+		Complete(gomock.Any(), entry).
+		DoAndReturn(func(context.Context, *domain.QueuedTask) error {
+			close(dropped)
+			return nil
+		})
+
+	processor := s.newProcessor(succeed)
+	defer processor.Shutdown(context.Background())
+
+	<-dropped
+	s.Equal(domain.TaskStatusCancelled, task.Status)
+}
+
+func (s *TaskProcessorTestSuite) TestHandle_DropsEntryOfDeletedTask() {
+	entry := &domain.QueuedTask{ID: 10, TaskID: 1}
+	dropped := make(chan struct{})
+
+	s.expectSingleClaim(entry)
+	s.mockRepository.EXPECT().FindByID(gomock.Any(), entry.TaskID).Return(nil, domain.ErrTaskNotFound)
+	s.mockQueue.EXPECT().
+		Complete(gomock.Any(), entry).
+		DoAndReturn(func(context.Context, *domain.QueuedTask) error {
+			close(dropped)
+			return nil
+		})
+
+	processor := s.newProcessor(succeed)
+	defer processor.Shutdown(context.Background())
+
+	<-dropped
+}
+
+func (s *TaskProcessorTestSuite) TestShutdown_WaitsForRunningTask() {
+	task := &domain.Task{ID: 1, Title: "Test Task", Type: "test", Status: domain.TaskStatusPending}
+	entry := &domain.QueuedTask{ID: 10, TaskID: task.ID}
+	started := make(chan struct{})
+
+	s.expectSingleClaim(entry)
+	s.mockRepository.EXPECT().FindByID(gomock.Any(), task.ID).Return(task, nil)
+	s.mockQueue.EXPECT().ExtendLease(gomock.Any(), entry, taskLeaseDuration).Return(nil)
+	s.mockRepository.EXPECT().
+		Update(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, t *domain.Task, _ ...string) error {
+			if t.Status == domain.TaskStatusProcessing {
+				close(started)
+			}
+			return nil
+		}).
+		Times(2)
+	s.mockQueue.EXPECT().Complete(gomock.Any(), entry).Return(nil)
+
+	processor := s.newProcessor(func(ctx context.Context, t *domain.Task) (*domain.TaskResult, error) {
+		time.Sleep(200 * time.Millisecond)
+		return nil, nil
+	})
+
+	<-started
+	processor.Shutdown(context.Background())
+	s.Equal(domain.TaskStatusCompleted, task.Status)
+}
+
+func (s *TaskProcessorTestSuite) TestShutdown_StopsRunningTaskWhenContextEnds() {
+	task := &domain.Task{ID: 1, Title: "Test Task", Type: "test", Status: domain.TaskStatusPending}
+	entry := &domain.QueuedTask{ID: 10, TaskID: task.ID}
+	started := make(chan struct{})
+
+	s.expectSingleClaim(entry)
+	s.mockRepository.EXPECT().FindByID(gomock.Any(), task.ID).Return(task, nil)
+	s.mockQueue.EXPECT().ExtendLease(gomock.Any(), entry, taskLeaseDuration).Return(nil)
+	s.mockRepository.EXPECT().
+		Update(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, t *domain.Task, _ ...string) error {
+			close(started)
+			return nil
+		})
+
+	// The entry is neither completed nor retried, so its lease runs out
+	processor := s.newProcessor(func(ctx context.Context, t *domain.Task) (*domain.TaskResult, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	s.ErrorIs(processor.Shutdown(ctx), context.DeadlineExceeded)
+	s.Equal(domain.TaskStatusProcessing, task.Status)
+}
+
+func (s *TaskProcessorTestSuite) TestCancel_PendingTask() {
+	s.mockQueue.EXPECT().Claim(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, domain.ErrQueueEmpty).AnyTimes()
+	s.mockQueue.EXPECT().Remove(gomock.Any(), uint(1)).Return(nil)
+
+	processor := s.newProcessor(succeed)
+	defer processor.Shutdown(context.Background())
+
+	s.NoError(processor.Cancel(context.Background(), 1))
+}
+
+func (s *TaskProcessorTestSuite) TestCancel_RunningTask() {
+	task := &domain.Task{ID: 1, Title: "Test Task", Type: "test", Status: domain.TaskStatusPending}
+	entry := &domain.QueuedTask{ID: 10, TaskID: task.ID}
+	started := make(chan struct{})
+
+	s.expectSingleClaim(entry)
+	s.mockRepository.EXPECT().FindByID(gomock.Any(), task.ID).Return(task, nil)
+	s.mockQueue.EXPECT().ExtendLease(gomock.Any(), entry, taskLeaseDuration).Return(nil)
+
+	// Only the processing status is written; the canceller records the rest
+	s.mockRepository.EXPECT().
+		Update(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, t *domain.Task, _ ...string) error {
+			close(started)
+			return nil
+		})
+	s.mockQueue.EXPECT().Remove(gomock.Any(), task.ID).Return(nil)
+
+	processor := s.newProcessor(func(ctx context.Context, t *domain.Task) (*domain.TaskResult, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	<-started
+	s.NoError(processor.Cancel(context.Background(), task.ID))
+
+	start := time.Now()
+	processor.Shutdown(context.Background())
+	s.Less(time.Since(start), time.Second)
+}
+
+func (s *TaskProcessorTestSuite) TestHandle_RetriesFailedAttempt() {
+	task := &domain.Task{ID: 1, Title: "Test Task", Type: "test", Status: domain.TaskStatusPending}
+	entry := &domain.QueuedTask{ID: 10, TaskID: task.ID}
+	retried := make(chan time.Time)
+
+	s.expectSingleClaim(entry)
+	s.mockRepository.EXPECT().FindByID(gomock.Any(), task.ID).Return(task, nil)
+	s.mockQueue.EXPECT().ExtendLease(gomock.Any(), entry, taskLeaseDuration).Return(nil)
+	s.mockRepository.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	s.mockQueue.EXPECT().
+		Retry(gomock.Any(), entry, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *domain.QueuedTask, availableAt time.Time) error {
+			retried <- availableAt
+			return nil
+		})
+
+	processor := s.newProcessor(func(context.Context, *domain.Task) (*domain.TaskResult, error) {
+		return nil, errors.New("upstream unavailable")
+	})
+	defer processor.Shutdown(context.Background())
+
+	availableAt := <-retried
+	s.WithinDuration(time.Now().Add(time.Minute), availableAt, 5*time.Second)
+	s.Equal(domain.TaskStatusPending, task.Status)
+	s.Equal(1, task.Attempts)
+	s.Equal("upstream unavailable", task.LastError)
+}
+
+func (s *TaskProcessorTestSuite) TestHandle_BuriesTaskAfterLastAttempt() {
+	task := &domain.Task{ID: 1, Title: "Test Task", Type: "test", Status: domain.TaskStatusPending, Attempts: 1}
+	entry := &domain.QueuedTask{ID: 10, TaskID: task.ID}
+	buried := make(chan struct{})
+
+	s.expectSingleClaim(entry)
+	s.mockRepository.EXPECT().FindByID(gomock.Any(), task.ID).Return(task, nil)
+	s.mockQueue.EXPECT().ExtendLease(gomock.Any(), entry, taskLeaseDuration).Return(nil)
+	s.mockRepository.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	s.mockQueue.EXPECT().
+		Complete(gomock.Any(), entry).
+		DoAndReturn(func(context.Context, *domain.QueuedTask) error {
+			close(buried)
+			return nil
+		})
+
+	processor := s.newProcessor(func(context.Context, *domain.Task) (*domain.TaskResult, error) {
+		panic("boom")
+	})
+	defer processor.Shutdown(context.Background())
+
+	<-buried
+	s.Equal(domain.TaskStatusDead, task.Status)
+	s.Equal(2, task.Attempts)
+	s.Contains(task.LastError, "panicked: boom")
+}
+
+func (s *TaskProcessorTestSuite) TestHandle_TimesOutStuckHandler() {
+	task := &domain.Task{ID: 1, Title: "Test Task", Type: "test", Status: domain.TaskStatusPending, Timeout: 1}
+	entry := &domain.QueuedTask{ID: 10, TaskID: task.ID}
+	timedOut := make(chan struct{})
+
+	s.expectSingleClaim(entry)
+	s.mockRepository.EXPECT().FindByID(gomock.Any(), task.ID).Return(task, nil)
+	s.mockQueue.EXPECT().ExtendLease(gomock.Any(), entry, taskLeaseDuration).Return(nil)
+	s.mockRepository.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	s.mockQueue.EXPECT().
+		Complete(gomock.Any(), entry).
+		DoAndReturn(func(context.Context, *domain.QueuedTask) error {
+			close(timedOut)
+			return nil
+		})
+
+	// The handler ignores its context, so only abandoning it frees the worker
+	release := make(chan struct{})
+	defer close(release)
+	processor := s.newProcessor(func(context.Context, *domain.Task) (*domain.TaskResult, error) {
+		<-release
+		return nil, nil
+	})
+
+	<-timedOut
+	processor.Shutdown(context.Background())
+	s.Equal(domain.TaskStatusTimedOut, task.Status)
+	s.Equal(1, task.Attempts)
+	s.Equal("timed out after 1s", task.LastError)
+}
+
+func (s *TaskProcessorTestSuite) TestHandle_KeepsReportedProgress() {
+	task := &domain.Task{ID: 1, Title: "Test Task", Type: "test", Status: domain.TaskStatusPending, Progress: 40}
+	entry := &domain.QueuedTask{ID: 10, TaskID: task.ID}
+	finished := make(chan struct{})
+
+	s.expectSingleClaim(entry)
+	s.mockRepository.EXPECT().FindByID(gomock.Any(), task.ID).Return(task, nil)
+	s.mockQueue.EXPECT().ExtendLease(gomock.Any(), entry, taskLeaseDuration).Return(nil)
+	gomock.InOrder(
+		s.mockRepository.EXPECT().
+			Update(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, t *domain.Task, _ ...string) error {
+				s.Equal(0, t.Progress, "a new attempt starts its progress over")
+				return nil
+			}),
+		// Only the first of the quick reports is written right away
+		s.mockRepository.EXPECT().
+			UpdateProgress(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, t *domain.Task) error {
+				s.Equal(10, t.Progress)
+				s.Equal("one", t.ProgressMessage)
+				return nil
+			}),
+		s.mockRepository.EXPECT().
+			Update(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, t *domain.Task, _ ...string) error {
+				s.Equal(domain.TaskStatusCompleted, t.Status)
+				s.Equal(100, t.Progress)
+				s.Equal("three", t.ProgressMessage)
+				return nil
+			}),
+	)
+	s.mockEvents.EXPECT().PublishProgress(gomock.Any())
+	s.mockQueue.EXPECT().
+		Complete(gomock.Any(), entry).
+		DoAndReturn(func(context.Context, *domain.QueuedTask) error {
+			close(finished)
+			return nil
+		})
+
+	processor := s.newProcessor(func(ctx context.Context, _ *domain.Task) (*domain.TaskResult, error) {
+		ReportProgress(ctx, 10, "one")
+		ReportProgress(ctx, 20, "two")
+		ReportProgress(ctx, 30, "three")
+		return nil, nil
+	})
+
+	<-finished
+	processor.Shutdown(context.Background())
+}
+
+func (s *TaskProcessorTestSuite) TestHandle_StoresResult() {
+	task := &domain.Task{ID: 1, Title: "Test Task", Type: "test", Status: domain.TaskStatusPending}
+	entry := &domain.QueuedTask{ID: 10, TaskID: task.ID}
+	finished := make(chan struct{})
+
+	s.expectSingleClaim(entry)
+	s.mockRepository.EXPECT().FindByID(gomock.Any(), task.ID).Return(task, nil)
+	s.mockQueue.EXPECT().ExtendLease(gomock.Any(), entry, taskLeaseDuration).Return(nil)
+	s.mockResults.EXPECT().
+		Save(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, r *domain.TaskResult) error {
+			s.Equal(task.ID, r.TaskID)
+			s.Equal(domain.JSON(`{"ok":true}`), r.Data)
+			s.WithinDuration(time.Now().Add(time.Hour), r.ExpiresAt, 5*time.Second)
+			return nil
+		})
+	s.mockRepository.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	s.mockQueue.EXPECT().
+		Complete(gomock.Any(), entry).
+		DoAndReturn(func(context.Context, *domain.QueuedTask) error {
+			close(finished)
+			return nil
+		})
+
+	processor := s.newProcessor(func(context.Context, *domain.Task) (*domain.TaskResult, error) {
+		return &domain.TaskResult{Data: domain.JSON(`{"ok":true}`)}, nil
+	})
+	defer processor.Shutdown(context.Background())
+
+	<-finished
+	s.Equal(domain.TaskStatusCompleted, task.Status)
+}
+
+func (s *TaskProcessorTestSuite) TestHandle_RejectsOversizedResult() {
+	task := &domain.Task{ID: 1, Title: "Test Task", Type: "test", Status: domain.TaskStatusPending}
+	entry := &domain.QueuedTask{ID: 10, TaskID: task.ID}
+	failed := make(chan struct{})
+
+	s.expectSingleClaim(entry)
+	s.mockRepository.EXPECT().FindByID(gomock.Any(), task.ID).Return(task, nil)
+	s.mockQueue.EXPECT().ExtendLease(gomock.Any(), entry, taskLeaseDuration).Return(nil)
+	s.mockRepository.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	// The task fails on its first attempt instead of being retried
+	s.mockQueue.EXPECT().
+		Complete(gomock.Any(), entry).
+		DoAndReturn(func(context.Context, *domain.QueuedTask) error {
+			close(failed)
+			return nil
+		})
+
+	processor := s.newProcessor(func(context.Context, *domain.Task) (*domain.TaskResult, error) {
+		return &domain.TaskResult{Data: domain.JSON(`{"message":"far too long for the limit"}`)}, nil
+	})
+	defer processor.Shutdown(context.Background())
+
+	<-failed
+	s.Equal(domain.TaskStatusFailed, task.Status)
+	s.Contains(task.LastError, domain.ErrResultTooLarge.Error())
+}
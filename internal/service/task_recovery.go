@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golangwithgin/internal/domain"
+	"time"
+)
+
+// Recovery policies for tasks left unfinished by a previous run
+const (
+	RecoveryPolicyRequeue = "requeue"
+	RecoveryPolicyFail    = "fail"
+	RecoveryPolicyIgnore  = "ignore"
+)
+
+// RecoverySummary reports the outcome of a startup reconciliation
+type RecoverySummary struct {
+	Found    int
+	Requeued int
+	Failed   int
+	Ignored  int
+}
+
+// TaskRecoverer reconciles orphaned tasks when the server starts
+type TaskRecoverer struct {
+	repository domain.TaskRepository
+	queue      domain.TaskQueue
+	events     domain.TaskEventPublisher
+	policies   map[domain.TaskStatus]string
+}
+
+// NewTaskRecoverer creates a recoverer applying the given policies to
+// pending and processing tasks respectively. An empty policy means requeue.
+// Tasks it fails are published to events.
+func NewTaskRecoverer(repository domain.TaskRepository, queue domain.TaskQueue, events domain.TaskEventPublisher, pendingPolicy, processingPolicy string) (*TaskRecoverer, error) {
+	policies := map[domain.TaskStatus]string{
+		domain.TaskStatusPending:    pendingPolicy,
+		domain.TaskStatusProcessing: processingPolicy,
+	}
+	for status, policy := range policies {
+		switch policy {
+		case "":
+			policies[status] = RecoveryPolicyRequeue
+		case RecoveryPolicyRequeue, RecoveryPolicyFail, RecoveryPolicyIgnore:
+		default:
+			return nil, fmt.Errorf("invalid recovery policy %q for %s tasks", policy, status)
+		}
+	}
+
+	return &TaskRecoverer{
+		repository: repository,
+		queue:      queue,
+		events:     events,
+		policies:   policies,
+	}, nil
+}
+
+// Recover finds pending and processing tasks left behind by a stopped
+// server and requeues, fails or ignores them according to policy. A task is
+// only orphaned if it has no queue entry or its worker's lease has expired;
+// entries waiting for their run time, a retry or a free worker are left
+// alone.
+func (r *TaskRecoverer) Recover(ctx context.Context) (*RecoverySummary, error) {
+	tasks, err := r.repository.FindByStatus(ctx, domain.TaskStatusPending, domain.TaskStatusProcessing)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &RecoverySummary{}
+	now := time.Now()
+	for _, task := range tasks {
+		entry, err := r.queue.FindByTaskID(ctx, task.ID)
+		if err != nil && !errors.Is(err, domain.ErrTaskNotQueued) {
+			return summary, err
+		}
+		if entry != nil && !entry.LeaseExpired(now) {
+			// The task is waiting in the queue or another server instance
+			// is working on it
+			continue
+		}
+
+		summary.Found++
+		switch r.policies[task.Status] {
+		case RecoveryPolicyRequeue:
+			if err := r.queue.Enqueue(ctx, task); err != nil {
+				return summary, err
+			}
+			summary.Requeued++
+		case RecoveryPolicyFail:
+			task.Status = domain.TaskStatusFailed
+			task.UpdatedAt = now
+			if err := r.repository.Update(ctx, task); err != nil {
+				return summary, err
+			}
+			r.events.Publish(task)
+			if err := r.queue.Remove(ctx, task.ID); err != nil {
+				return summary, err
+			}
+			summary.Failed++
+		default:
+			summary.Ignored++
+		}
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"golangwithgin/internal/domain"
+	"golangwithgin/internal/domain/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+)
+
+type TaskRecoveryTestSuite struct {
+	suite.Suite
+	mockCtrl       *gomock.Controller
+	mockRepository *mocks.MockTaskRepository
+	mockQueue      *mocks.MockTaskQueue
+	mockEvents     *mocks.MockTaskEventPublisher
+}
+
+func TestTaskRecoverySuite(t *testing.T) {
+	suite.Run(t, new(TaskRecoveryTestSuite))
+}
+
+func (s *TaskRecoveryTestSuite) SetupTest() {
+	s.mockCtrl = gomock.NewController(s.T())
+	s.mockRepository = mocks.NewMockTaskRepository(s.mockCtrl)
+	s.mockQueue = mocks.NewMockTaskQueue(s.mockCtrl)
+	s.mockEvents = mocks.NewMockTaskEventPublisher(s.mockCtrl)
+}
+
+func (s *TaskRecoveryTestSuite) TearDownTest() {
+	s.mockCtrl.Finish()
+}
+
+func (s *TaskRecoveryTestSuite) TestNewTaskRecoverer_InvalidPolicy() {
+	_, err := NewTaskRecoverer(s.mockRepository, s.mockQueue, s.mockEvents, "requeue", "retry")
+	s.Error(err)
+}
+
+func (s *TaskRecoveryTestSuite) TestRecover_AppliesPolicies() {
+	pending := &domain.Task{ID: 1, Status: domain.TaskStatusPending}
+	processing := &domain.Task{ID: 2, Status: domain.TaskStatusProcessing}
+	leased := &domain.Task{ID: 3, Status: domain.TaskStatusProcessing}
+	expiredAt := time.Now().Add(-time.Minute)
+	expiresAt := time.Now().Add(time.Minute)
+
+	s.mockRepository.EXPECT().
+		FindByStatus(gomock.Any(), domain.TaskStatusPending, domain.TaskStatusProcessing).
+		Return([]*domain.Task{pending, processing, leased}, nil)
+	s.mockQueue.EXPECT().FindByTaskID(gomock.Any(), uint(1)).Return(nil, domain.ErrTaskNotQueued)
+	s.mockQueue.EXPECT().FindByTaskID(gomock.Any(), uint(2)).Return(&domain.QueuedTask{TaskID: 2, LeaseExpiresAt: &expiredAt}, nil)
+	s.mockQueue.EXPECT().FindByTaskID(gomock.Any(), uint(3)).Return(&domain.QueuedTask{TaskID: 3, LeaseExpiresAt: &expiresAt}, nil)
+
+	// Pending tasks are requeued
+	s.mockQueue.EXPECT().Enqueue(gomock.Any(), pending).Return(nil)
+
+	// Processing tasks are failed and dropped from the queue
+	s.mockRepository.EXPECT().
+		Update(gomock.Any(), processing).
+		DoAndReturn(func(_ context.Context, t *domain.Task, _ ...string) error {
+			s.Equal(domain.TaskStatusFailed, t.Status)
+			return nil
+		})
+	s.mockEvents.EXPECT().Publish(processing).Do(func(t *domain.Task) {
+		s.Equal(domain.TaskStatusFailed, t.Status)
+	})
+	s.mockQueue.EXPECT().Remove(gomock.Any(), uint(2)).Return(nil)
+
+	recoverer, err := NewTaskRecoverer(s.mockRepository, s.mockQueue, s.mockEvents, "requeue", "fail")
+	s.Require().NoError(err)
+
+	summary, err := recoverer.Recover(context.Background())
+	s.NoError(err)
+	s.Equal(&RecoverySummary{Found: 2, Requeued: 1, Failed: 1}, summary)
+}
+
+func (s *TaskRecoveryTestSuite) TestRecover_LeavesWaitingTasksQueued() {
+	delayed := &domain.Task{ID: 1, Status: domain.TaskStatusPending}
+	ready := &domain.Task{ID: 2, Status: domain.TaskStatusPending}
+
+	s.mockRepository.EXPECT().
+		FindByStatus(gomock.Any(), domain.TaskStatusPending, domain.TaskStatusProcessing).
+		Return([]*domain.Task{delayed, ready}, nil)
+	s.mockQueue.EXPECT().FindByTaskID(gomock.Any(), uint(1)).Return(&domain.QueuedTask{TaskID: 1, AvailableAt: time.Now().Add(time.Hour)}, nil)
+	s.mockQueue.EXPECT().FindByTaskID(gomock.Any(), uint(2)).Return(&domain.QueuedTask{TaskID: 2, AvailableAt: time.Now()}, nil)
+
+	// Neither task is failed or removed from the queue
+	recoverer, err := NewTaskRecoverer(s.mockRepository, s.mockQueue, s.mockEvents, "fail", "fail")
+	s.Require().NoError(err)
+
+	summary, err := recoverer.Recover(context.Background())
+	s.NoError(err)
+	s.Equal(&RecoverySummary{}, summary)
+	s.Equal(domain.TaskStatusPending, delayed.Status)
+}
+
+func (s *TaskRecoveryTestSuite) TestRecover_Ignore() {
+	s.mockRepository.EXPECT().
+		FindByStatus(gomock.Any(), domain.TaskStatusPending, domain.TaskStatusProcessing).
+		Return([]*domain.Task{{ID: 1, Status: domain.TaskStatusPending}}, nil)
+	s.mockQueue.EXPECT().FindByTaskID(gomock.Any(), uint(1)).Return(nil, domain.ErrTaskNotQueued)
+
+	recoverer, err := NewTaskRecoverer(s.mockRepository, s.mockQueue, s.mockEvents, "ignore", "")
+	s.Require().NoError(err)
+
+	summary, err := recoverer.Recover(context.Background())
+	s.NoError(err)
+	s.Equal(&RecoverySummary{Found: 1, Ignored: 1}, summary)
+}
@@ -1,67 +1,310 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"golangwithgin/internal/domain"
+	"strings"
 	"time"
 )
 
 // taskService implements the TaskService interface
 type taskService struct {
-	repository domain.TaskRepository
-	processor  domain.TaskProcessor
+	repository  domain.TaskRepository
+	results     domain.TaskResultRepository
+	processor   domain.TaskProcessor
+	queue       domain.TaskQueue
+	registry    *HandlerRegistry
+	events      domain.TaskEventPublisher
+	retryPolicy RetryPolicy
 }
 
-// NewTaskService creates a new task service
-func NewTaskService(repository domain.TaskRepository, processor domain.TaskProcessor) domain.TaskService {
+// NewTaskService creates a new task service. Only tasks whose type has a
+// handler in registry are accepted, and tasks submitted without an attempt
+// limit get the one from retryPolicy. Status changes made by the service are
+// published to events, and task listings show positions in queue.
+func NewTaskService(repository domain.TaskRepository, results domain.TaskResultRepository, processor domain.TaskProcessor, queue domain.TaskQueue, registry *HandlerRegistry, events domain.TaskEventPublisher, retryPolicy RetryPolicy) domain.TaskService {
 	return &taskService{
-		repository: repository,
-		processor:  processor,
+		repository:  repository,
+		results:     results,
+		processor:   processor,
+		queue:       queue,
+		registry:    registry,
+		events:      events,
+		retryPolicy: retryPolicy.withDefaults(),
 	}
 }
 
-func (s *taskService) SubmitTask(task *domain.Task) error {
-	// Set initial task state
-	task.Status = "pending"
+// SubmitTask stores a new pending task and hands it to the processor, which
+// owns every later status change until the task finishes. A task with
+// dependencies is stored blocked instead and left to the dependency
+// resolver, which sees it through the published event.
+func (s *taskService) SubmitTask(ctx context.Context, task *domain.Task) error {
+	if err := validateTask(task, s.registry); err != nil {
+		return err
+	}
+	if err := s.checkDependencies(ctx, task); err != nil {
+		return err
+	}
+	// Set initial task state; blocked tasks take no room in their queue
+	task.Status = domain.TaskStatusPending
+	if len(task.DependsOn) > 0 {
+		task.Status = domain.TaskStatusBlocked
+	}
+	if err := s.processor.Admit(ctx, []*domain.Task{task}); err != nil {
+		return err
+	}
+	if task.MaxAttempts == 0 {
+		task.MaxAttempts = s.retryPolicy.MaxAttempts
+	}
+	if task.RunAt != nil && task.RunAt.IsZero() {
+		task.RunAt = nil
+	}
+
+	task.WorkflowID = nil
+	task.Attempts = 0
+	task.LastError = ""
+	task.Progress = 0
+	task.ProgressMessage = ""
 	task.CreatedAt = time.Now()
 	task.UpdatedAt = time.Now()
 
 	// Save task to database
-	if err := s.repository.Create(task); err != nil {
+	if err := s.repository.Create(ctx, task); err != nil {
 		return err
 	}
+	// Once the task exists, queue it even if the caller goes away
+	ctx = context.WithoutCancel(ctx)
+	s.events.Publish(task)
+	if len(task.DependsOn) > 0 {
+		return nil
+	}
 
-	// Process task asynchronously
-	go func() {
-		// Update task status to processing
-		task.Status = "processing"
+	// Queue the task for asynchronous processing
+	if err := s.processor.Process(ctx, task); err != nil {
+		task.Status = domain.TaskStatusFailed
 		task.UpdatedAt = time.Now()
-		if err := s.repository.Update(task); err != nil {
-			return
+		if s.repository.Update(ctx, task) == nil {
+			s.events.Publish(task)
 		}
+		return err
+	}
+
+	return nil
+}
 
-		// Process the task
-		if err := s.processor.Process(task); err != nil {
-			task.Status = "failed"
-		} else {
-			task.Status = "completed"
+// checkDependencies removes duplicate dependencies of a new task and makes
+// sure they all exist and belong to the task's owner
+func (s *taskService) checkDependencies(ctx context.Context, task *domain.Task) error {
+	if len(task.DependsOn) == 0 {
+		return nil
+	}
+
+	seen := make(map[uint]bool, len(task.DependsOn))
+	ids := task.DependsOn[:0]
+	for _, id := range task.DependsOn {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
 		}
+	}
+	task.DependsOn = ids
 
-		// Update task in database after processing
-		task.UpdatedAt = time.Now()
-		if err := s.repository.Update(task); err != nil {
-			// Log error but don't return it since we're in a goroutine
-			// In a production system, you'd want to handle this error properly
-			return
+	parents, err := s.repository.FindByIDsForUser(ctx, ids, task.UserID)
+	if err != nil {
+		return err
+	}
+	for _, parent := range parents {
+		delete(seen, parent.ID)
+	}
+	for id := range seen {
+		return fmt.Errorf("%w: dependency %d not found", domain.ErrInvalidTask, id)
+	}
+	return nil
+}
+
+// validateTask checks the caller-supplied fields of a new task, defaulting
+// its type and queue
+func validateTask(task *domain.Task, registry *HandlerRegistry) error {
+	if task.MaxAttempts < 0 {
+		return fmt.Errorf("%w: max_attempts must not be negative", domain.ErrInvalidTask)
+	}
+	if task.Timeout < 0 {
+		return fmt.Errorf("%w: timeout must not be negative", domain.ErrInvalidTask)
+	}
+	if task.Priority < domain.MinTaskPriority || task.Priority > domain.MaxTaskPriority {
+		return fmt.Errorf("%w: priority must be between %d and %d", domain.ErrInvalidTask, domain.MinTaskPriority, domain.MaxTaskPriority)
+	}
+	if !task.DependencyPolicy.IsValid() {
+		return fmt.Errorf("%w: unknown dependency policy %q", domain.ErrInvalidTask, task.DependencyPolicy)
+	}
+	if task.DependencyPolicy == "" {
+		task.DependencyPolicy = domain.DependencyPolicyCancel
+	}
+	if task.Type == "" {
+		task.Type = DefaultTaskType
+	}
+	if _, ok := registry.Lookup(task.Type); !ok {
+		return fmt.Errorf("%w: unknown task type %q, expected one of %s", domain.ErrInvalidTask, task.Type, strings.Join(registry.Types(), ", "))
+	}
+	if task.Queue == "" {
+		task.Queue = domain.DefaultQueue
+	}
+	return nil
+}
+
+func (s *taskService) GetTaskStatus(ctx context.Context, userID, id uint) (*domain.Task, error) {
+	return s.repository.FindByIDForUser(ctx, id, userID)
+}
+
+// GetTaskResult returns the stored result of a completed task owned by the
+// user
+func (s *taskService) GetTaskResult(ctx context.Context, userID, id uint) (*domain.TaskResult, error) {
+	task, err := s.repository.FindByIDForUser(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != domain.TaskStatusCompleted {
+		return nil, domain.ErrTaskNotCompleted
+	}
+	return s.results.FindByTaskID(ctx, task.ID)
+}
+
+// GetAllTasks returns a page of the user's tasks, applying default sorting
+// and page size where the filter leaves them unset
+func (s *taskService) GetAllTasks(ctx context.Context, filter domain.TaskFilter) (*domain.TaskPage, error) {
+	switch filter.SortBy {
+	case "":
+		filter.SortBy = "created_at"
+	case "created_at", "updated_at":
+	default:
+		return nil, fmt.Errorf("%w: cannot sort by %q", domain.ErrInvalidTaskFilter, filter.SortBy)
+	}
+
+	switch filter.SortOrder {
+	case "":
+		filter.SortOrder = "desc"
+	case "asc", "desc":
+	default:
+		return nil, fmt.Errorf("%w: unknown sort order %q", domain.ErrInvalidTaskFilter, filter.SortOrder)
+	}
+
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, fmt.Errorf("%w: unknown status %q", domain.ErrInvalidTaskFilter, filter.Status)
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = domain.DefaultTaskPageSize
+	}
+	if filter.Limit > domain.MaxTaskPageSize {
+		filter.Limit = domain.MaxTaskPageSize
+	}
+
+	page, err := s.repository.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setQueuePositions(ctx, page.Tasks); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// setQueuePositions fills in the queue position of pending tasks
+func (s *taskService) setQueuePositions(ctx context.Context, tasks []*domain.Task) error {
+	var pending []uint
+	for _, task := range tasks {
+		if task.Status == domain.TaskStatusPending {
+			pending = append(pending, task.ID)
 		}
-	}()
+	}
+	if len(pending) == 0 {
+		return nil
+	}
 
+	positions, err := s.queue.Positions(ctx, pending)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if position, ok := positions[task.ID]; ok {
+			task.QueuePosition = &position
+		}
+	}
 	return nil
 }
 
-func (s *taskService) GetTaskStatus(id uint) (*domain.Task, error) {
-	return s.repository.FindByID(id)
+// CancelTask cancels a task owned by the user that has not finished yet
+func (s *taskService) CancelTask(ctx context.Context, userID, id uint) (*domain.Task, error) {
+	task, err := s.repository.FindByIDForUser(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if task.IsFinished() {
+		return nil, domain.ErrTaskFinished
+	}
+
+	// Record the cancellation first so a worker finishing at the same time
+	// cannot overwrite it; the state machine rejects the later transition
+	task.Status = domain.TaskStatusCancelled
+	task.UpdatedAt = time.Now()
+	err = s.repository.Update(ctx, task)
+	if errors.Is(err, domain.ErrInvalidTaskStatus) {
+		return nil, domain.ErrTaskFinished
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.events.Publish(task)
+
+	if err := s.processor.Cancel(context.WithoutCancel(ctx), task.ID); err != nil {
+		return nil, err
+	}
+	return task, nil
 }
 
-func (s *taskService) GetAllTasks() ([]*domain.Task, error) {
-	return s.repository.FindAll()
-} 
\ No newline at end of file
+// RequeueTask gives a dead task owned by the user a fresh set of attempts.
+// It is refused like a new submission while the task's queue is full or
+// draining, and a task that cannot be queued again fails instead of
+// staying pending.
+func (s *taskService) RequeueTask(ctx context.Context, userID, id uint) (*domain.Task, error) {
+	task, err := s.repository.FindByIDForUser(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != domain.TaskStatusDead {
+		return nil, domain.ErrTaskNotDead
+	}
+	if err := s.processor.Admit(ctx, []*domain.Task{task}); err != nil {
+		return nil, err
+	}
+
+	task.Status = domain.TaskStatusPending
+	task.Attempts = 0
+	task.LastError = ""
+	task.Progress = 0
+	task.ProgressMessage = ""
+	task.UpdatedAt = time.Now()
+	err = s.repository.Update(ctx, task, "attempts", "last_error", "progress", "progress_message")
+	if errors.Is(err, domain.ErrInvalidTaskStatus) {
+		return nil, domain.ErrTaskNotDead
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Once the task is pending, queue it even if the caller goes away
+	ctx = context.WithoutCancel(ctx)
+	s.events.Publish(task)
+
+	if err := s.processor.Process(ctx, task); err != nil {
+		task.Status = domain.TaskStatusFailed
+		task.LastError = "could not queue task"
+		task.UpdatedAt = time.Now()
+		if s.repository.Update(ctx, task, "last_error") == nil {
+			s.events.Publish(task)
+		}
+		return nil, err
+	}
+	return task, nil
+}
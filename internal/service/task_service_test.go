@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"golangwithgin/internal/domain"
 	"golangwithgin/internal/domain/mocks"
@@ -15,7 +16,10 @@ type TaskServiceTestSuite struct {
 	suite.Suite
 	mockCtrl       *gomock.Controller
 	mockRepository *mocks.MockTaskRepository
+	mockResults    *mocks.MockTaskResultRepository
 	mockProcessor  *mocks.MockTaskProcessor
+	mockQueue      *mocks.MockTaskQueue
+	mockEvents     *mocks.MockTaskEventPublisher
 	service        domain.TaskService
 }
 
@@ -26,58 +30,113 @@ func TestTaskServiceSuite(t *testing.T) {
 func (s *TaskServiceTestSuite) SetupTest() {
 	s.mockCtrl = gomock.NewController(s.T())
 	s.mockRepository = mocks.NewMockTaskRepository(s.mockCtrl)
+	s.mockResults = mocks.NewMockTaskResultRepository(s.mockCtrl)
 	s.mockProcessor = mocks.NewMockTaskProcessor(s.mockCtrl)
-	s.service = NewTaskService(s.mockRepository, s.mockProcessor)
+	s.mockQueue = mocks.NewMockTaskQueue(s.mockCtrl)
+	s.mockEvents = mocks.NewMockTaskEventPublisher(s.mockCtrl)
+	registry := NewHandlerRegistry()
+	RegisterDefaultHandlers(registry)
+	s.service = NewTaskService(s.mockRepository, s.mockResults, s.mockProcessor, s.mockQueue, registry, s.mockEvents, RetryPolicy{MaxAttempts: 4})
 }
 
 func (s *TaskServiceTestSuite) TearDownTest() {
 	s.mockCtrl.Finish()
 }
 
+// expectPublished expects events for the given statuses in order
+func (s *TaskServiceTestSuite) expectPublished(statuses ...domain.TaskStatus) {
+	calls := make([]*gomock.Call, 0, len(statuses))
+	for _, status := range statuses {
+		status := status
+		calls = append(calls, s.mockEvents.EXPECT().
+			Publish(gomock.Any()).
+			Do(func(t *domain.Task) {
+				s.Equal(status, t.Status)
+			}))
+	}
+	gomock.InOrder(calls...)
+}
+
 func (s *TaskServiceTestSuite) TestSubmitTask_Success() {
 	task := &domain.Task{
 		Title:       "Test Task",
 		Description: "Test Description",
+		// Progress is only ever reported by the task's handler
+		Progress:        50,
+		ProgressMessage: "Half way",
 	}
 
+	s.mockProcessor.EXPECT().Admit(gomock.Any(), gomock.Len(1)).Return(nil)
 	// Expect Create call
 	s.mockRepository.EXPECT().
-		Create(gomock.Any()).
-		DoAndReturn(func(t *domain.Task) error {
-			s.Equal("pending", t.Status)
+		Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, t *domain.Task) error {
+			s.Equal(domain.TaskStatusPending, t.Status)
+			s.Equal(4, t.MaxAttempts)
+			s.Equal(DefaultTaskType, t.Type)
+			s.Zero(t.Progress)
+			s.Empty(t.ProgressMessage)
 			s.NotZero(t.CreatedAt)
 			s.NotZero(t.UpdatedAt)
 			return nil
 		})
 
-	// Expect Update call for processing status
-	s.mockRepository.EXPECT().
-		Update(gomock.Any()).
-		DoAndReturn(func(t *domain.Task) error {
-			s.Equal("processing", t.Status)
-			s.NotZero(t.UpdatedAt)
-			return nil
-		})
-
-	// Expect Process call
+	// The pending status is published; the processor owns later changes
+	s.expectPublished(domain.TaskStatusPending)
 	s.mockProcessor.EXPECT().
-		Process(gomock.Any()).
+		Process(gomock.Any(), gomock.Any()).
 		Return(nil)
 
-	// Expect Update call for completed status
-	s.mockRepository.EXPECT().
-		Update(gomock.Any()).
-		DoAndReturn(func(t *domain.Task) error {
-			s.Equal("completed", t.Status)
-			s.NotZero(t.UpdatedAt)
+	err := s.service.SubmitTask(context.Background(), task)
+	s.NoError(err)
+	s.Equal(domain.TaskStatusPending, task.Status)
+}
+
+func (s *TaskServiceTestSuite) TestSubmitTask_Scheduled() {
+	runAt := time.Now().Add(time.Hour)
+	task := &domain.Task{Title: "Later", RunAt: &runAt}
+
+	s.mockProcessor.EXPECT().Admit(gomock.Any(), gomock.Len(1)).Return(nil)
+	s.mockRepository.EXPECT().Create(gomock.Any(), task).Return(nil)
+	s.expectPublished(domain.TaskStatusPending)
+	s.mockProcessor.EXPECT().
+		Process(gomock.Any(), task).
+		DoAndReturn(func(_ context.Context, t *domain.Task) error {
+			s.Require().NotNil(t.RunAt)
+			s.True(runAt.Equal(*t.RunAt))
 			return nil
 		})
 
-	err := s.service.SubmitTask(task)
-	s.NoError(err)
+	s.NoError(s.service.SubmitTask(context.Background(), task))
+	s.Equal(domain.TaskStatusPending, task.Status)
+}
+
+func (s *TaskServiceTestSuite) TestSubmitTask_Blocked() {
+	task := &domain.Task{UserID: 1, Title: "After", DependsOn: []uint{4, 5, 4}}
 
-	// Wait for goroutine to complete
-	time.Sleep(100 * time.Millisecond)
+	s.mockRepository.EXPECT().
+		FindByIDsForUser(gomock.Any(), []uint{4, 5}, uint(1)).
+		Return([]*domain.Task{{ID: 4}, {ID: 5}}, nil)
+	s.mockProcessor.EXPECT().Admit(gomock.Any(), gomock.Len(1)).Return(nil)
+	s.mockRepository.EXPECT().Create(gomock.Any(), task).Return(nil)
+	s.expectPublished(domain.TaskStatusBlocked)
+
+	// Left to the dependency resolver rather than processed
+	s.NoError(s.service.SubmitTask(context.Background(), task))
+	s.Equal(domain.TaskStatusBlocked, task.Status)
+	s.Equal([]uint{4, 5}, task.DependsOn)
+}
+
+func (s *TaskServiceTestSuite) TestSubmitTask_UnknownDependency() {
+	task := &domain.Task{UserID: 1, Title: "After", DependsOn: []uint{4, 5}}
+
+	s.mockRepository.EXPECT().
+		FindByIDsForUser(gomock.Any(), []uint{4, 5}, uint(1)).
+		Return([]*domain.Task{{ID: 4}}, nil)
+
+	err := s.service.SubmitTask(context.Background(), task)
+	s.ErrorIs(err, domain.ErrInvalidTask)
+	s.Contains(err.Error(), "dependency 5 not found")
 }
 
 func (s *TaskServiceTestSuite) TestSubmitTask_CreateError() {
@@ -87,11 +146,12 @@ func (s *TaskServiceTestSuite) TestSubmitTask_CreateError() {
 	}
 
 	expectedErr := errors.New("create error")
+	s.mockProcessor.EXPECT().Admit(gomock.Any(), gomock.Len(1)).Return(nil)
 	s.mockRepository.EXPECT().
-		Create(gomock.Any()).
+		Create(gomock.Any(), gomock.Any()).
 		Return(expectedErr)
 
-	err := s.service.SubmitTask(task)
+	err := s.service.SubmitTask(context.Background(), task)
 	s.Equal(expectedErr, err)
 }
 
@@ -101,59 +161,282 @@ func (s *TaskServiceTestSuite) TestSubmitTask_ProcessError() {
 		Description: "Test Description",
 	}
 
+	s.mockProcessor.EXPECT().Admit(gomock.Any(), gomock.Len(1)).Return(nil)
 	// Expect Create call
 	s.mockRepository.EXPECT().
-		Create(gomock.Any()).
-		Return(nil)
-
-	// Expect Update call for processing status
-	s.mockRepository.EXPECT().
-		Update(gomock.Any()).
+		Create(gomock.Any(), gomock.Any()).
 		Return(nil)
+	s.expectPublished(domain.TaskStatusPending, domain.TaskStatusFailed)
 
 	// Expect Process call with error
 	expectedErr := errors.New("process error")
 	s.mockProcessor.EXPECT().
-		Process(gomock.Any()).
+		Process(gomock.Any(), gomock.Any()).
 		Return(expectedErr)
 
 	// Expect Update call for failed status
 	s.mockRepository.EXPECT().
-		Update(gomock.Any()).
-		DoAndReturn(func(t *domain.Task) error {
-			s.Equal("failed", t.Status)
+		Update(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, t *domain.Task, _ ...string) error {
+			s.Equal(domain.TaskStatusFailed, t.Status)
 			s.NotZero(t.UpdatedAt)
 			return nil
 		})
 
-	err := s.service.SubmitTask(task)
-	s.NoError(err)
+	err := s.service.SubmitTask(context.Background(), task)
+	s.Equal(expectedErr, err)
+}
 
-	// Wait for goroutine to complete
-	time.Sleep(100 * time.Millisecond)
+func (s *TaskServiceTestSuite) TestSubmitTask_QueueFull() {
+	task := &domain.Task{Title: "Test Task"}
+
+	// Nothing is stored while the queue is full
+	s.mockProcessor.EXPECT().Admit(gomock.Any(), gomock.Len(1)).Return(domain.ErrQueueFull)
+
+	err := s.service.SubmitTask(context.Background(), task)
+	s.ErrorIs(err, domain.ErrQueueFull)
 }
 
 func (s *TaskServiceTestSuite) TestGetTaskStatus() {
-	expectedTask := &domain.Task{ID: 1, Title: "Test Task"}
+	expectedTask := &domain.Task{ID: 1, UserID: 7, Title: "Test Task"}
 	s.mockRepository.EXPECT().
-		FindByID(uint(1)).
+		FindByIDForUser(gomock.Any(), uint(1), uint(7)).
 		Return(expectedTask, nil)
 
-	task, err := s.service.GetTaskStatus(1)
+	task, err := s.service.GetTaskStatus(context.Background(), 7, 1)
 	s.NoError(err)
 	s.Equal(expectedTask, task)
 }
 
+func (s *TaskServiceTestSuite) TestGetTaskStatus_OtherUser() {
+	s.mockRepository.EXPECT().
+		FindByIDForUser(gomock.Any(), uint(1), uint(8)).
+		Return(nil, domain.ErrTaskNotFound)
+
+	task, err := s.service.GetTaskStatus(context.Background(), 8, 1)
+	s.ErrorIs(err, domain.ErrTaskNotFound)
+	s.Nil(task)
+}
+
 func (s *TaskServiceTestSuite) TestGetAllTasks() {
-	expectedTasks := []*domain.Task{
-		{ID: 1, Title: "Task 1"},
-		{ID: 2, Title: "Task 2"},
+	expectedPage := &domain.TaskPage{
+		Tasks: []*domain.Task{
+			{ID: 1, UserID: 7, Title: "Task 1"},
+			{ID: 2, UserID: 7, Title: "Task 2"},
+		},
+		Total: 2,
+	}
+	s.mockRepository.EXPECT().
+		List(gomock.Any(), domain.TaskFilter{
+			UserID:    7,
+			SortBy:    "created_at",
+			SortOrder: "desc",
+			Limit:     domain.DefaultTaskPageSize,
+		}).
+		Return(expectedPage, nil)
+
+	page, err := s.service.GetAllTasks(context.Background(), domain.TaskFilter{UserID: 7})
+	s.NoError(err)
+	s.Equal(expectedPage, page)
+}
+
+func (s *TaskServiceTestSuite) TestGetAllTasks_QueuePositions() {
+	s.mockRepository.EXPECT().
+		List(gomock.Any(), gomock.Any()).
+		Return(&domain.TaskPage{
+			Tasks: []*domain.Task{
+				{ID: 1, Status: domain.TaskStatusPending},
+				{ID: 2, Status: domain.TaskStatusProcessing},
+				{ID: 3, Status: domain.TaskStatusPending},
+			},
+		}, nil)
+	// Task 3 is waiting out a retry backoff and has no position
+	s.mockQueue.EXPECT().Positions(gomock.Any(), []uint{1, 3}).Return(map[uint]int{1: 4}, nil)
+
+	page, err := s.service.GetAllTasks(context.Background(), domain.TaskFilter{UserID: 7})
+	s.Require().NoError(err)
+	s.Require().NotNil(page.Tasks[0].QueuePosition)
+	s.Equal(4, *page.Tasks[0].QueuePosition)
+	s.Nil(page.Tasks[1].QueuePosition)
+	s.Nil(page.Tasks[2].QueuePosition)
+}
+
+func (s *TaskServiceTestSuite) TestGetAllTasks_CapsPageSize() {
+	s.mockRepository.EXPECT().
+		List(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, filter domain.TaskFilter) (*domain.TaskPage, error) {
+			s.Equal(domain.MaxTaskPageSize, filter.Limit)
+			s.Equal("updated_at", filter.SortBy)
+			s.Equal("asc", filter.SortOrder)
+			return &domain.TaskPage{}, nil
+		})
+
+	_, err := s.service.GetAllTasks(context.Background(), domain.TaskFilter{
+		UserID:    7,
+		SortBy:    "updated_at",
+		SortOrder: "asc",
+		Limit:     1000,
+	})
+	s.NoError(err)
+}
+
+func (s *TaskServiceTestSuite) TestGetAllTasks_InvalidStatus() {
+	_, err := s.service.GetAllTasks(context.Background(), domain.TaskFilter{UserID: 7, Status: "stuck"})
+	s.ErrorIs(err, domain.ErrInvalidTaskFilter)
+}
+
+func (s *TaskServiceTestSuite) TestGetAllTasks_InvalidSort() {
+	_, err := s.service.GetAllTasks(context.Background(), domain.TaskFilter{UserID: 7, SortBy: "title"})
+	s.ErrorIs(err, domain.ErrInvalidTaskFilter)
+
+	_, err = s.service.GetAllTasks(context.Background(), domain.TaskFilter{UserID: 7, SortOrder: "sideways"})
+	s.ErrorIs(err, domain.ErrInvalidTaskFilter)
+}
+
+func (s *TaskServiceTestSuite) TestCancelTask() {
+	task := &domain.Task{ID: 1, UserID: 7, Status: domain.TaskStatusProcessing}
+	s.mockRepository.EXPECT().
+		FindByIDForUser(gomock.Any(), uint(1), uint(7)).
+		Return(task, nil)
+	s.mockRepository.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, t *domain.Task, _ ...string) error {
+			s.Equal(domain.TaskStatusCancelled, t.Status)
+			return nil
+		})
+	s.expectPublished(domain.TaskStatusCancelled)
+	s.mockProcessor.EXPECT().
+		Cancel(gomock.Any(), uint(1)).
+		Return(nil)
+
+	cancelled, err := s.service.CancelTask(context.Background(), 7, 1)
+	s.NoError(err)
+	s.Equal(domain.TaskStatusCancelled, cancelled.Status)
+}
+
+func (s *TaskServiceTestSuite) TestCancelTask_AlreadyFinished() {
+	s.mockRepository.EXPECT().
+		FindByIDForUser(gomock.Any(), uint(1), uint(7)).
+		Return(&domain.Task{ID: 1, UserID: 7, Status: domain.TaskStatusCompleted}, nil)
+
+	_, err := s.service.CancelTask(context.Background(), 7, 1)
+	s.ErrorIs(err, domain.ErrTaskFinished)
+}
+
+func (s *TaskServiceTestSuite) TestCancelTask_FinishedConcurrently() {
+	s.mockRepository.EXPECT().
+		FindByIDForUser(gomock.Any(), uint(1), uint(7)).
+		Return(&domain.Task{ID: 1, UserID: 7, Status: domain.TaskStatusProcessing}, nil)
+	s.mockRepository.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		Return(domain.ErrInvalidTaskStatus)
+
+	_, err := s.service.CancelTask(context.Background(), 7, 1)
+	s.ErrorIs(err, domain.ErrTaskFinished)
+}
+
+func (s *TaskServiceTestSuite) TestSubmitTask_NegativeMaxAttempts() {
+	err := s.service.SubmitTask(context.Background(), &domain.Task{Title: "Test Task", MaxAttempts: -1})
+	s.ErrorIs(err, domain.ErrInvalidTask)
+}
+
+func (s *TaskServiceTestSuite) TestSubmitTask_NegativeTimeout() {
+	err := s.service.SubmitTask(context.Background(), &domain.Task{Title: "Test Task", Timeout: -1})
+	s.ErrorIs(err, domain.ErrInvalidTask)
+}
+
+func (s *TaskServiceTestSuite) TestSubmitTask_PriorityOutOfRange() {
+	err := s.service.SubmitTask(context.Background(), &domain.Task{Title: "Test Task", Priority: domain.MaxTaskPriority + 1})
+	s.ErrorIs(err, domain.ErrInvalidTask)
+
+	err = s.service.SubmitTask(context.Background(), &domain.Task{Title: "Test Task", Priority: -1})
+	s.ErrorIs(err, domain.ErrInvalidTask)
+}
+
+func (s *TaskServiceTestSuite) TestSubmitTask_UnknownType() {
+	err := s.service.SubmitTask(context.Background(), &domain.Task{Title: "Test Task", Type: "missing"})
+	s.ErrorIs(err, domain.ErrInvalidTask)
+}
+
+func (s *TaskServiceTestSuite) TestRequeueTask() {
+	task := &domain.Task{ID: 1, UserID: 7, Status: domain.TaskStatusDead, Attempts: 3, MaxAttempts: 3,
+		LastError: "boom", Progress: 40, ProgressMessage: "halfway"}
+	s.mockRepository.EXPECT().FindByIDForUser(gomock.Any(), uint(1), uint(7)).Return(task, nil)
+	s.mockProcessor.EXPECT().Admit(gomock.Any(), []*domain.Task{task}).Return(nil)
+	s.mockRepository.EXPECT().
+		Update(gomock.Any(), gomock.Any(), "attempts", "last_error", "progress", "progress_message").
+		DoAndReturn(func(_ context.Context, t *domain.Task, _ ...string) error {
+			s.Equal(domain.TaskStatusPending, t.Status)
+			s.Zero(t.Attempts)
+			s.Empty(t.LastError)
+			s.Zero(t.Progress)
+			s.Empty(t.ProgressMessage)
+			return nil
+		})
+	s.expectPublished(domain.TaskStatusPending)
+	s.mockProcessor.EXPECT().Process(gomock.Any(), task).Return(nil)
+
+	requeued, err := s.service.RequeueTask(context.Background(), 7, 1)
+	s.NoError(err)
+	s.Equal(domain.TaskStatusPending, requeued.Status)
+}
+
+func (s *TaskServiceTestSuite) TestRequeueTask_FailsTaskThatCannotBeQueued() {
+	task := &domain.Task{ID: 1, UserID: 7, Status: domain.TaskStatusDead, Attempts: 3, MaxAttempts: 3}
+	expectedErr := errors.New("queue unavailable")
+	s.mockRepository.EXPECT().FindByIDForUser(gomock.Any(), uint(1), uint(7)).Return(task, nil)
+	s.mockProcessor.EXPECT().Admit(gomock.Any(), []*domain.Task{task}).Return(nil)
+	gomock.InOrder(
+		s.mockRepository.EXPECT().Update(gomock.Any(), task, gomock.Any()).Return(nil),
+		s.mockRepository.EXPECT().Update(gomock.Any(), task, "last_error").Return(nil),
+	)
+	s.expectPublished(domain.TaskStatusPending, domain.TaskStatusFailed)
+	s.mockProcessor.EXPECT().Process(gomock.Any(), task).Return(expectedErr)
+
+	_, err := s.service.RequeueTask(context.Background(), 7, 1)
+	s.ErrorIs(err, expectedErr)
+	s.Equal(domain.TaskStatusFailed, task.Status)
+}
+
+func (s *TaskServiceTestSuite) TestRequeueTask_RefusedWhileQueueCannotTakeIt() {
+	for _, expectedErr := range []error{domain.ErrQueueFull, domain.ErrQueueDraining} {
+		task := &domain.Task{ID: 1, UserID: 7, Status: domain.TaskStatusDead, Attempts: 3, MaxAttempts: 3}
+		s.mockRepository.EXPECT().FindByIDForUser(gomock.Any(), uint(1), uint(7)).Return(task, nil)
+		s.mockProcessor.EXPECT().Admit(gomock.Any(), []*domain.Task{task}).Return(expectedErr)
+
+		_, err := s.service.RequeueTask(context.Background(), 7, 1)
+		s.ErrorIs(err, expectedErr)
+		// The task stays dead, so it can be requeued once there is room
+		s.Equal(domain.TaskStatusDead, task.Status)
 	}
+}
+
+func (s *TaskServiceTestSuite) TestRequeueTask_NotDead() {
 	s.mockRepository.EXPECT().
-		FindAll().
-		Return(expectedTasks, nil)
+		FindByIDForUser(gomock.Any(), uint(1), uint(7)).
+		Return(&domain.Task{ID: 1, UserID: 7, Status: domain.TaskStatusCompleted}, nil)
 
-	tasks, err := s.service.GetAllTasks()
+	_, err := s.service.RequeueTask(context.Background(), 7, 1)
+	s.ErrorIs(err, domain.ErrTaskNotDead)
+}
+
+func (s *TaskServiceTestSuite) TestGetTaskResult() {
+	expected := &domain.TaskResult{TaskID: 1, Data: domain.JSON(`{"ok":true}`)}
+	s.mockRepository.EXPECT().
+		FindByIDForUser(gomock.Any(), uint(1), uint(7)).
+		Return(&domain.Task{ID: 1, UserID: 7, Status: domain.TaskStatusCompleted}, nil)
+	s.mockResults.EXPECT().FindByTaskID(gomock.Any(), uint(1)).Return(expected, nil)
+
+	result, err := s.service.GetTaskResult(context.Background(), 7, 1)
 	s.NoError(err)
-	s.Equal(expectedTasks, tasks)
-} 
\ No newline at end of file
+	s.Equal(expected, result)
+}
+
+func (s *TaskServiceTestSuite) TestGetTaskResult_NotCompleted() {
+	s.mockRepository.EXPECT().
+		FindByIDForUser(gomock.Any(), uint(1), uint(7)).
+		Return(&domain.Task{ID: 1, UserID: 7, Status: domain.TaskStatusProcessing}, nil)
+
+	_, err := s.service.GetTaskResult(context.Background(), 7, 1)
+	s.ErrorIs(err, domain.ErrTaskNotCompleted)
+}
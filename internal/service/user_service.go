@@ -29,6 +29,9 @@ func (s *userService) Register(user *domain.User) error {
 	}
 	user.Password = string(hashedPassword)
 
+	// Admins are only made by promoting an existing user
+	user.Role = domain.UserRoleUser
+
 	// Set timestamps
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golangwithgin/internal/domain"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWebhookWorkers = 2
+	defaultWebhookTimeout = 10 * time.Second
+	// webhookLeaseMargin is how much longer than the request timeout a
+	// delivery stays claimed, so that no other worker sends it meanwhile
+	webhookLeaseMargin = 30 * time.Second
+)
+
+// Headers sent with every webhook delivery. The signature is the hex-encoded
+// HMAC-SHA256 of the request body keyed by the webhook secret, prefixed with
+// "sha256=".
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// WebhookConfig controls how webhook deliveries are sent and retried.
+// Deliveries are only sent to public addresses unless AllowPrivateTargets is
+// set.
+type WebhookConfig struct {
+	Retry               RetryPolicy
+	Timeout             time.Duration
+	Workers             int
+	AllowPrivateTargets bool
+}
+
+// webhookPayload is the body of a webhook delivery
+type webhookPayload struct {
+	Event      string       `json:"event"`
+	OccurredAt time.Time    `json:"occurred_at"`
+	Task       *domain.Task `json:"task"`
+}
+
+// WebhookDispatcher turns task events into webhook deliveries and sends them.
+// Deliveries are stored before they are sent, so pending ones survive a
+// restart, and failed attempts are retried with exponential backoff.
+type WebhookDispatcher struct {
+	webhooks    domain.WebhookRepository
+	deliveries  domain.WebhookDeliveryRepository
+	client      *http.Client
+	retryPolicy RetryPolicy
+	lease       time.Duration
+	workers     int
+	wg          sync.WaitGroup
+	stopChan    chan struct{}
+	wakeup      chan struct{}
+}
+
+// NewWebhookDispatcher creates a dispatcher and starts its workers
+func NewWebhookDispatcher(webhooks domain.WebhookRepository, deliveries domain.WebhookDeliveryRepository, config WebhookConfig) *WebhookDispatcher {
+	if config.Timeout <= 0 {
+		config.Timeout = defaultWebhookTimeout
+	}
+	if config.Workers <= 0 {
+		config.Workers = defaultWebhookWorkers
+	}
+
+	dispatcher := &WebhookDispatcher{
+		webhooks:    webhooks,
+		deliveries:  deliveries,
+		client:      newWebhookClient(config.Timeout, config.AllowPrivateTargets),
+		retryPolicy: config.Retry.withDefaults(),
+		lease:       config.Timeout + webhookLeaseMargin,
+		workers:     config.Workers,
+		stopChan:    make(chan struct{}),
+		wakeup:      make(chan struct{}, 1),
+	}
+
+	for i := 0; i < dispatcher.workers; i++ {
+		dispatcher.wg.Add(1)
+		go dispatcher.worker()
+	}
+	return dispatcher
+}
+
+// Publish queues a delivery of the task's new status to each of the owner's
+// active webhooks that subscribes to it
+func (d *WebhookDispatcher) Publish(task *domain.Task) {
+	ctx := context.Background()
+	webhooks, err := d.webhooks.FindActiveByUser(ctx, task.UserID)
+	if err != nil || len(webhooks) == 0 {
+		return
+	}
+
+	now := time.Now()
+	event := domain.WebhookEventName(task.Status)
+	payload, err := json.Marshal(webhookPayload{Event: event, OccurredAt: now, Task: task})
+	if err != nil {
+		return
+	}
+
+	var deliveries []*domain.WebhookDelivery
+	for _, webhook := range webhooks {
+		if !webhook.Accepts(event) {
+			continue
+		}
+		deliveries = append(deliveries, &domain.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			TaskID:        task.ID,
+			Event:         event,
+			Payload:       payload,
+			Status:        domain.WebhookDeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+	}
+	if len(deliveries) == 0 || d.deliveries.Create(ctx, deliveries) != nil {
+		return
+	}
+
+	// Wake an idle worker instead of waiting for the next poll
+	select {
+	case d.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// PublishProgress does nothing: webhooks are only sent on status changes
+func (d *WebhookDispatcher) PublishProgress(task *domain.Task) {}
+
+func (d *WebhookDispatcher) worker() {
+	defer d.wg.Done()
+
+	ctx := context.Background()
+	ticker := time.NewTicker(queuePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stopChan:
+			return
+		default:
+		}
+
+		delivery, err := d.deliveries.Claim(ctx, d.lease)
+		if err != nil {
+			select {
+			case <-d.wakeup:
+			case <-ticker.C:
+			case <-d.stopChan:
+				return
+			}
+			continue
+		}
+
+		d.deliver(ctx, delivery)
+	}
+}
+
+// deliver makes one attempt at a delivery and records the outcome
+func (d *WebhookDispatcher) deliver(ctx context.Context, delivery *domain.WebhookDelivery) {
+	webhook, err := d.webhooks.FindByID(ctx, delivery.WebhookID)
+	if errors.Is(err, domain.ErrWebhookNotFound) {
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.LastError = "webhook was deleted"
+		delivery.UpdatedAt = time.Now()
+		d.deliveries.Update(ctx, delivery)
+		return
+	}
+	if err != nil {
+		// The lease expires and the delivery is attempted again
+		return
+	}
+
+	delivery.Attempts++
+	delivery.ResponseCode, err = d.send(ctx, webhook, delivery)
+	delivery.UpdatedAt = time.Now()
+
+	switch {
+	case err == nil:
+		delivery.Status = domain.WebhookDeliverySucceeded
+		delivery.LastError = ""
+	case delivery.Attempts >= d.retryPolicy.MaxAttempts:
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.LastError = err.Error()
+	default:
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = time.Now().Add(d.retryPolicy.Backoff(delivery.Attempts))
+	}
+	d.deliveries.Update(ctx, delivery)
+}
+
+// send posts the delivery's payload and returns the response status code
+func (d *WebhookDispatcher) send(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Shutdown stops claiming deliveries and waits for in-flight ones to finish
+func (d *WebhookDispatcher) Shutdown() {
+	close(d.stopChan)
+	d.wg.Wait()
+}
+
+// SignWebhookPayload returns the signature header value for a payload
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"golangwithgin/internal/domain"
+	"golangwithgin/internal/domain/mocks"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestDispatcher returns a dispatcher without workers so tests drive
+// deliveries directly
+func newTestDispatcher(webhooks domain.WebhookRepository, deliveries domain.WebhookDeliveryRepository) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhooks:    webhooks,
+		deliveries:  deliveries,
+		client:      &http.Client{Timeout: time.Second},
+		retryPolicy: RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Minute, MaxBackoff: time.Hour, Multiplier: 2},
+		wakeup:      make(chan struct{}, 1),
+	}
+}
+
+func TestWebhookDispatcher_PublishQueuesSubscribedWebhooks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	webhooks := mocks.NewMockWebhookRepository(ctrl)
+	deliveries := mocks.NewMockWebhookDeliveryRepository(ctrl)
+	webhooks.EXPECT().FindActiveByUser(gomock.Any(), uint(7)).Return([]*domain.Webhook{
+		{ID: 1, Events: domain.StringList{"task.completed"}},
+		{ID: 2, Events: domain.StringList{"task.failed"}},
+		{ID: 3},
+	}, nil)
+	deliveries.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, created []*domain.WebhookDelivery) error {
+			assert.Len(t, created, 2)
+			assert.Equal(t, uint(1), created[0].WebhookID)
+			assert.Equal(t, uint(3), created[1].WebhookID)
+			for _, delivery := range created {
+				assert.Equal(t, "task.completed", delivery.Event)
+				assert.Equal(t, domain.WebhookDeliveryPending, delivery.Status)
+				assert.Contains(t, string(delivery.Payload), `"event":"task.completed"`)
+			}
+			return nil
+		})
+
+	dispatcher := newTestDispatcher(webhooks, deliveries)
+	dispatcher.Publish(&domain.Task{ID: 5, UserID: 7, Status: domain.TaskStatusCompleted})
+
+	select {
+	case <-dispatcher.wakeup:
+	default:
+		t.Fatal("no worker was woken")
+	}
+}
+
+func TestWebhookDispatcher_DeliverSignsPayload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	payload := []byte(`{"event":"task.completed"}`)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, payload, body)
+		assert.Equal(t, "task.completed", r.Header.Get(WebhookEventHeader))
+		assert.Equal(t, "9", r.Header.Get(WebhookDeliveryHeader))
+		assert.Equal(t, SignWebhookPayload("secret", payload), r.Header.Get(WebhookSignatureHeader))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	webhooks := mocks.NewMockWebhookRepository(ctrl)
+	deliveries := mocks.NewMockWebhookDeliveryRepository(ctrl)
+	webhooks.EXPECT().FindByID(gomock.Any(), uint(1)).Return(&domain.Webhook{ID: 1, URL: receiver.URL, Secret: "secret"}, nil)
+	deliveries.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, delivery *domain.WebhookDelivery) error {
+			assert.Equal(t, domain.WebhookDeliverySucceeded, delivery.Status)
+			assert.Equal(t, 1, delivery.Attempts)
+			assert.Equal(t, http.StatusNoContent, delivery.ResponseCode)
+			return nil
+		})
+
+	dispatcher := newTestDispatcher(webhooks, deliveries)
+	dispatcher.deliver(context.Background(), &domain.WebhookDelivery{ID: 9, WebhookID: 1, Event: "task.completed", Payload: payload, Status: domain.WebhookDeliveryPending})
+}
+
+func TestWebhookDispatcher_DeliverRetriesThenFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	webhooks := mocks.NewMockWebhookRepository(ctrl)
+	deliveries := mocks.NewMockWebhookDeliveryRepository(ctrl)
+	webhooks.EXPECT().FindByID(gomock.Any(), uint(1)).Return(&domain.Webhook{ID: 1, URL: receiver.URL, Secret: "secret"}, nil).Times(2)
+	deliveries.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	dispatcher := newTestDispatcher(webhooks, deliveries)
+	delivery := &domain.WebhookDelivery{ID: 9, WebhookID: 1, Event: "task.failed", Status: domain.WebhookDeliveryPending}
+
+	dispatcher.deliver(context.Background(), delivery)
+	assert.Equal(t, domain.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, http.StatusInternalServerError, delivery.ResponseCode)
+	assert.Contains(t, delivery.LastError, "500")
+	assert.WithinDuration(t, time.Now().Add(time.Minute), delivery.NextAttemptAt, 5*time.Second)
+
+	dispatcher.deliver(context.Background(), delivery)
+	assert.Equal(t, domain.WebhookDeliveryFailed, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+}
+
+func TestWebhookDispatcher_DeliverToDeletedWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	webhooks := mocks.NewMockWebhookRepository(ctrl)
+	deliveries := mocks.NewMockWebhookDeliveryRepository(ctrl)
+	webhooks.EXPECT().FindByID(gomock.Any(), uint(1)).Return(nil, domain.ErrWebhookNotFound)
+	deliveries.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+	dispatcher := newTestDispatcher(webhooks, deliveries)
+	delivery := &domain.WebhookDelivery{ID: 9, WebhookID: 1, Status: domain.WebhookDeliveryPending}
+	dispatcher.deliver(context.Background(), delivery)
+
+	assert.Equal(t, domain.WebhookDeliveryFailed, delivery.Status)
+	assert.Equal(t, 0, delivery.Attempts)
+}
+
+func TestWebhookDispatcher_DeliverRefusesPrivateAddress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	called := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer receiver.Close()
+
+	webhooks := mocks.NewMockWebhookRepository(ctrl)
+	deliveries := mocks.NewMockWebhookDeliveryRepository(ctrl)
+	webhooks.EXPECT().FindByID(gomock.Any(), uint(1)).Return(&domain.Webhook{ID: 1, URL: receiver.URL, Secret: "secret"}, nil)
+	deliveries.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+	dispatcher := newTestDispatcher(webhooks, deliveries)
+	dispatcher.client = newWebhookClient(time.Second, false)
+	delivery := &domain.WebhookDelivery{ID: 9, WebhookID: 1, Status: domain.WebhookDeliveryPending}
+	dispatcher.deliver(context.Background(), delivery)
+
+	assert.False(t, called)
+	assert.Contains(t, delivery.LastError, errPrivateWebhookTarget.Error())
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"golangwithgin/internal/domain"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDeliveryPageSize = 50
+	maxDeliveryPageSize     = 200
+	webhookLookupTimeout    = 5 * time.Second
+)
+
+// webhookService implements the WebhookService interface
+type webhookService struct {
+	repository domain.WebhookRepository
+	deliveries domain.WebhookDeliveryRepository
+	// lookupIP resolves webhook hosts to check that they are public; it is
+	// nil when private targets are allowed
+	lookupIP lookupIPFunc
+}
+
+// NewWebhookService creates a new webhook service. Unless
+// allowPrivateTargets is set, webhook URLs must point at public addresses.
+func NewWebhookService(repository domain.WebhookRepository, deliveries domain.WebhookDeliveryRepository, allowPrivateTargets bool) domain.WebhookService {
+	service := &webhookService{
+		repository: repository,
+		deliveries: deliveries,
+	}
+	if !allowPrivateTargets {
+		service.lookupIP = lookupIP
+	}
+	return service
+}
+
+// Create registers a webhook. A secret is generated if none is given.
+func (s *webhookService) Create(ctx context.Context, webhook *domain.Webhook) error {
+	if err := s.validate(ctx, webhook); err != nil {
+		return err
+	}
+	if webhook.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			return err
+		}
+		webhook.Secret = secret
+	}
+
+	webhook.ID = 0
+	webhook.CreatedAt = time.Now()
+	webhook.UpdatedAt = time.Now()
+	return s.repository.Create(ctx, webhook)
+}
+
+func (s *webhookService) Get(ctx context.Context, userID, id uint) (*domain.Webhook, error) {
+	return s.repository.FindByIDForUser(ctx, id, userID)
+}
+
+func (s *webhookService) List(ctx context.Context, userID uint) ([]*domain.Webhook, error) {
+	return s.repository.ListByUser(ctx, userID)
+}
+
+// Update replaces a webhook's settings, keeping its secret unless a new one
+// is given
+func (s *webhookService) Update(ctx context.Context, webhook *domain.Webhook) error {
+	if err := s.validate(ctx, webhook); err != nil {
+		return err
+	}
+
+	current, err := s.repository.FindByIDForUser(ctx, webhook.ID, webhook.UserID)
+	if err != nil {
+		return err
+	}
+	if webhook.Secret == "" {
+		webhook.Secret = current.Secret
+	}
+
+	webhook.CreatedAt = current.CreatedAt
+	webhook.UpdatedAt = time.Now()
+	return s.repository.Update(ctx, webhook)
+}
+
+func (s *webhookService) Delete(ctx context.Context, userID, id uint) error {
+	return s.repository.Delete(ctx, id, userID)
+}
+
+// ListDeliveries returns the most recent deliveries to a webhook owned by
+// the user
+func (s *webhookService) ListDeliveries(ctx context.Context, userID, id uint, limit int) ([]*domain.WebhookDelivery, error) {
+	webhook, err := s.repository.FindByIDForUser(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultDeliveryPageSize
+	}
+	if limit > maxDeliveryPageSize {
+		limit = maxDeliveryPageSize
+	}
+	return s.deliveries.ListByWebhook(ctx, webhook.ID, limit)
+}
+
+func (s *webhookService) validate(ctx context.Context, webhook *domain.Webhook) error {
+	target, err := url.Parse(webhook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", domain.ErrInvalidWebhook)
+	}
+	if s.lookupIP != nil {
+		ctx, cancel := context.WithTimeout(ctx, webhookLookupTimeout)
+		defer cancel()
+		if err := checkWebhookHost(ctx, s.lookupIP, target.Hostname()); err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrInvalidWebhook, err)
+		}
+	}
+
+	for _, event := range webhook.Events {
+		status := domain.TaskStatus(strings.TrimPrefix(event, domain.WebhookEventPrefix))
+		if !strings.HasPrefix(event, domain.WebhookEventPrefix) || !status.IsValid() {
+			return fmt.Errorf("%w: unknown event %q", domain.ErrInvalidWebhook, event)
+		}
+	}
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
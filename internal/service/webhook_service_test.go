@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golangwithgin/internal/domain"
+	"golangwithgin/internal/domain/mocks"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestWebhookService returns a service that resolves example.com to a
+// public address and internal.example.com and localhost to private ones
+func newTestWebhookService(repository domain.WebhookRepository, deliveries domain.WebhookDeliveryRepository) domain.WebhookService {
+	addresses := map[string]string{
+		"example.com":          "93.184.215.14",
+		"internal.example.com": "10.0.0.5",
+		"localhost":            "127.0.0.1",
+	}
+	service := NewWebhookService(repository, deliveries, false).(*webhookService)
+	service.lookupIP = func(_ context.Context, host string) ([]net.IP, error) {
+		address, ok := addresses[host]
+		if !ok {
+			return nil, errors.New("no such host")
+		}
+		return []net.IP{net.ParseIP(address)}, nil
+	}
+	return service
+}
+
+func TestWebhookService_CreateValidates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := newTestWebhookService(mocks.NewMockWebhookRepository(ctrl), mocks.NewMockWebhookDeliveryRepository(ctrl))
+
+	invalid := []*domain.Webhook{
+		{URL: "not a url"},
+		{URL: "ftp://example.com/hook"},
+		{URL: "/relative/hook"},
+		{URL: "https://example.com/hook", Events: domain.StringList{"task.exploded"}},
+		{URL: "https://example.com/hook", Events: domain.StringList{"completed"}},
+		// Targets inside the server's network
+		{URL: "http://127.0.0.1:8080/hook"},
+		{URL: "http://[::1]/hook"},
+		{URL: "http://169.254.169.254/latest/meta-data"},
+		{URL: "http://192.168.1.10/hook"},
+		{URL: "http://100.64.0.1/hook"},
+		{URL: "http://100.127.255.254/hook"},
+		{URL: "http://0.1.2.3/hook"},
+		{URL: "http://[::ffff:100.64.0.1]/hook"},
+		{URL: "http://localhost/hook"},
+		{URL: "https://internal.example.com/hook"},
+		{URL: "https://unknown.example.com/hook"},
+	}
+	for _, webhook := range invalid {
+		assert.ErrorIs(t, service.Create(context.Background(), webhook), domain.ErrInvalidWebhook, webhook.URL)
+	}
+}
+
+func TestWebhookService_CreateGeneratesSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repository := mocks.NewMockWebhookRepository(ctrl)
+	repository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	service := newTestWebhookService(repository, mocks.NewMockWebhookDeliveryRepository(ctrl))
+	webhook := &domain.Webhook{UserID: 1, URL: "https://example.com/hook", Events: domain.StringList{"task.completed"}}
+
+	assert.NoError(t, service.Create(context.Background(), webhook))
+	assert.Len(t, webhook.Secret, 64)
+}
+
+func TestWebhookService_UpdateKeepsSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repository := mocks.NewMockWebhookRepository(ctrl)
+	repository.EXPECT().FindByIDForUser(gomock.Any(), uint(3), uint(1)).Return(&domain.Webhook{ID: 3, UserID: 1, Secret: "original"}, nil)
+	repository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+	service := newTestWebhookService(repository, mocks.NewMockWebhookDeliveryRepository(ctrl))
+	webhook := &domain.Webhook{ID: 3, UserID: 1, URL: "https://example.com/hook"}
+
+	assert.NoError(t, service.Update(context.Background(), webhook))
+	assert.Equal(t, "original", webhook.Secret)
+}
+
+func TestWebhookService_UpdateUnknownWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repository := mocks.NewMockWebhookRepository(ctrl)
+	repository.EXPECT().FindByIDForUser(gomock.Any(), uint(3), uint(2)).Return(nil, domain.ErrWebhookNotFound)
+
+	service := newTestWebhookService(repository, mocks.NewMockWebhookDeliveryRepository(ctrl))
+	err := service.Update(context.Background(), &domain.Webhook{ID: 3, UserID: 2, URL: "https://example.com/hook"})
+
+	assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+}
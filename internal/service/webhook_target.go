@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// errPrivateWebhookTarget refuses webhook targets inside the server's own
+// network, such as localhost, cloud metadata endpoints or private ranges
+var errPrivateWebhookTarget = errors.New("webhook target is not a public address")
+
+// reservedNetworks are the ranges net.IP has no predicate for: "this"
+// network and the shared address space carriers use for NAT
+var reservedNetworks = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),
+	mustParseCIDR("100.64.0.0/10"),
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// isPublicAddress reports whether webhooks may be delivered to ip
+func isPublicAddress(ip net.IP) bool {
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified()
+}
+
+// lookupIPFunc resolves a host name to its addresses
+type lookupIPFunc func(ctx context.Context, host string) ([]net.IP, error)
+
+func lookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	return net.DefaultResolver.LookupIP(ctx, "ip", host)
+}
+
+// checkWebhookHost refuses a host if it, or any address it resolves to, is
+// not public
+func checkWebhookHost(ctx context.Context, lookup lookupIPFunc, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublicAddress(ip) {
+			return errPrivateWebhookTarget
+		}
+		return nil
+	}
+
+	ips, err := lookup(ctx, host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve %s", host)
+	}
+	for _, ip := range ips {
+		if !isPublicAddress(ip) {
+			return errPrivateWebhookTarget
+		}
+	}
+	return nil
+}
+
+// refusePrivateAddresses is a net.Dialer control function that refuses
+// connections to addresses that are not public. Checking when dialing
+// catches hosts that resolve to another address than when the webhook was
+// registered.
+func refusePrivateAddresses(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicAddress(ip) {
+		return fmt.Errorf("%w: %s", errPrivateWebhookTarget, host)
+	}
+	return nil
+}
+
+// newWebhookClient returns the client deliveries are sent with. Unless
+// allowPrivate is set, it only connects to public addresses and ignores
+// proxy settings, which would otherwise hide the address it connects to.
+func newWebhookClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	if allowPrivate {
+		return &http.Client{Timeout: timeout}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   refusePrivateAddresses,
+	}).DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
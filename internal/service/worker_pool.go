@@ -0,0 +1,160 @@
+package service
+
+import (
+	"golangwithgin/internal/domain"
+	"sync"
+	"time"
+)
+
+// QueueConfig sets up the worker pool of a named queue
+type QueueConfig struct {
+	// MinWorkers and MaxWorkers bound how many of the queue's tasks run at
+	// once on each server
+	MinWorkers int
+	MaxWorkers int
+	// Capacity is how many tasks may wait in the queue before new
+	// submissions are refused
+	Capacity int
+	// RateLimit is the most tasks each server starts per second; zero
+	// means no limit
+	RateLimit float64
+	// UserConcurrency is the most tasks of one user that run at once
+	// across all servers; zero means no limit. Workers claiming at the same
+	// moment may briefly overshoot it.
+	UserConcurrency int
+}
+
+func (c QueueConfig) withDefaults() QueueConfig {
+	if c.MaxWorkers <= 0 {
+		c.MaxWorkers = max(DefaultMaxWorkers, c.MinWorkers)
+	}
+	if c.MinWorkers <= 0 {
+		c.MinWorkers = min(DefaultMinWorkers, c.MaxWorkers)
+	}
+	if c.MaxWorkers < c.MinWorkers {
+		c.MaxWorkers = c.MinWorkers
+	}
+	if c.Capacity <= 0 {
+		c.Capacity = DefaultQueueCapacity
+	}
+	if c.RateLimit < 0 {
+		c.RateLimit = 0
+	}
+	if c.UserConcurrency < 0 {
+		c.UserConcurrency = 0
+	}
+	return c
+}
+
+// workerPool runs the tasks of one named queue. Its worker counts and state
+// are guarded by the processor's mutex.
+type workerPool struct {
+	name    string
+	config  QueueConfig
+	metrics *poolMetrics
+	wakeup  chan struct{}
+	// retire holds one token per worker asked to stop
+	retire chan struct{}
+
+	workers  int
+	retiring int
+	busy     int
+	// active counts the workers claiming or running a task, so that a
+	// draining pool knows when it is done
+	active int
+	state  domain.QueueState
+
+	// Rate limited pools space their starts startEvery apart
+	startMu    sync.Mutex
+	startEvery time.Duration
+	nextStart  time.Time
+
+	// served holds when each user last had a task claimed, so that users
+	// take turns
+	servedMu sync.Mutex
+	served   map[uint]time.Time
+}
+
+func newWorkerPool(name string, config QueueConfig) *workerPool {
+	pool := &workerPool{
+		name:    name,
+		config:  config,
+		metrics: newPoolMetrics(name),
+		wakeup:  make(chan struct{}, 1),
+		retire:  make(chan struct{}, config.MaxWorkers),
+		state:   domain.QueueStateRunning,
+		served:  make(map[uint]time.Time),
+	}
+	if config.RateLimit > 0 {
+		pool.startEvery = time.Duration(float64(time.Second) / config.RateLimit)
+	}
+	return pool
+}
+
+// reserveStart takes the next start slot if it is due at now, or returns
+// how long until it is
+func (p *workerPool) reserveStart(now time.Time) (time.Duration, bool) {
+	if p.startEvery == 0 {
+		return 0, true
+	}
+
+	p.startMu.Lock()
+	defer p.startMu.Unlock()
+
+	if now.Before(p.nextStart) {
+		return p.nextStart.Sub(now), false
+	}
+	p.nextStart = now.Add(p.startEvery)
+	return 0, true
+}
+
+// releaseStart gives back the slot reserved at now when nothing was
+// claimed with it, unless another worker has reserved one since
+func (p *workerPool) releaseStart(now time.Time) {
+	if p.startEvery == 0 {
+		return
+	}
+
+	p.startMu.Lock()
+	defer p.startMu.Unlock()
+
+	if p.nextStart.Equal(now.Add(p.startEvery)) {
+		p.nextStart = now
+	}
+}
+
+// lastServed returns when each user with tasks in the queue last had one
+// claimed by this pool, and forgets the users without any
+func (p *workerPool) lastServed(shares []domain.UserShare) map[uint]time.Time {
+	p.servedMu.Lock()
+	defer p.servedMu.Unlock()
+
+	served := make(map[uint]time.Time, len(shares))
+	for _, share := range shares {
+		if at, ok := p.served[share.UserID]; ok {
+			served[share.UserID] = at
+		}
+	}
+	p.served = served
+
+	copied := make(map[uint]time.Time, len(served))
+	for userID, at := range served {
+		copied[userID] = at
+	}
+	return copied
+}
+
+// markServed records that a task of the user was claimed at now
+func (p *workerPool) markServed(userID uint, now time.Time) {
+	p.servedMu.Lock()
+	defer p.servedMu.Unlock()
+	p.served[userID] = now
+}
+
+// wake rouses an idle worker instead of waiting for the next poll
+func (p *workerPool) wake() {
+	select {
+	case p.wakeup <- struct{}{}:
+	default:
+	}
+}
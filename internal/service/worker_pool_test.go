@@ -0,0 +1,56 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueueConfig_WithDefaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		config QueueConfig
+		want   QueueConfig
+	}{
+		{"empty", QueueConfig{}, QueueConfig{MinWorkers: DefaultMinWorkers, MaxWorkers: DefaultMaxWorkers, Capacity: DefaultQueueCapacity}},
+		{"small concurrency lowers min", QueueConfig{MaxWorkers: 1}, QueueConfig{MinWorkers: 1, MaxWorkers: 1, Capacity: DefaultQueueCapacity}},
+		{"max below min", QueueConfig{MinWorkers: 5, MaxWorkers: 3, Capacity: 10}, QueueConfig{MinWorkers: 5, MaxWorkers: 5, Capacity: 10}},
+		{"negative rate limit", QueueConfig{MinWorkers: 1, MaxWorkers: 2, Capacity: 10, RateLimit: -1}, QueueConfig{MinWorkers: 1, MaxWorkers: 2, Capacity: 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.config.withDefaults())
+		})
+	}
+}
+
+func TestWorkerPool_SpacesStartsByRateLimit(t *testing.T) {
+	pool := newWorkerPool("limited", QueueConfig{MinWorkers: 1, MaxWorkers: 1, RateLimit: 4})
+	now := time.Now()
+
+	_, ok := pool.reserveStart(now)
+	assert.True(t, ok)
+
+	delay, ok := pool.reserveStart(now.Add(100 * time.Millisecond))
+	assert.False(t, ok)
+	assert.Equal(t, 150*time.Millisecond, delay)
+
+	_, ok = pool.reserveStart(now.Add(250 * time.Millisecond))
+	assert.True(t, ok)
+
+	// A slot that claimed nothing is handed back
+	pool.releaseStart(now.Add(250 * time.Millisecond))
+	_, ok = pool.reserveStart(now.Add(260 * time.Millisecond))
+	assert.True(t, ok)
+}
+
+func TestWorkerPool_UnlimitedStartsAreAlwaysDue(t *testing.T) {
+	pool := newWorkerPool("unlimited", QueueConfig{MinWorkers: 1, MaxWorkers: 1})
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		_, ok := pool.reserveStart(now)
+		assert.True(t, ok)
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"golangwithgin/internal/domain"
+	"time"
+)
+
+// workflowService implements the WorkflowService interface
+type workflowService struct {
+	repository  domain.WorkflowRepository
+	tasks       domain.TaskRepository
+	processor   domain.TaskProcessor
+	registry    *HandlerRegistry
+	events      domain.TaskEventPublisher
+	retryPolicy RetryPolicy
+}
+
+// NewWorkflowService creates a new workflow service. Tasks of a workflow are
+// validated like tasks submitted on their own; tasks without dependencies
+// are queued right away and the rest are left to the dependency resolver,
+// which must be part of events. Tasks that cannot be queued are failed in
+// tasks.
+func NewWorkflowService(repository domain.WorkflowRepository, tasks domain.TaskRepository, processor domain.TaskProcessor, registry *HandlerRegistry, events domain.TaskEventPublisher, retryPolicy RetryPolicy) domain.WorkflowService {
+	return &workflowService{
+		repository:  repository,
+		tasks:       tasks,
+		processor:   processor,
+		registry:    registry,
+		events:      events,
+		retryPolicy: retryPolicy.withDefaults(),
+	}
+}
+
+// Submit stores the workflow and all of its tasks, rejecting unknown keys
+// and dependency cycles. Only the tasks without dependencies have to fit in
+// their queues. Once the workflow is stored it is returned even if some of
+// its tasks could not be queued; those tasks fail, and their dependents
+// with them according to their dependency policy.
+func (s *workflowService) Submit(ctx context.Context, workflow *domain.Workflow, tasks []domain.WorkflowTask) error {
+	if len(tasks) == 0 {
+		return fmt.Errorf("%w: a workflow needs at least one task", domain.ErrInvalidWorkflow)
+	}
+
+	order, parents, err := sortWorkflowTasks(tasks)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	sorted := make([]*domain.Task, len(order))
+	for i, index := range order {
+		task := tasks[index].Task
+		if err := validateTask(task, s.registry); err != nil {
+			return fmt.Errorf("task %q: %w", tasks[index].Key, err)
+		}
+		if task.MaxAttempts == 0 {
+			task.MaxAttempts = s.retryPolicy.MaxAttempts
+		}
+		if task.RunAt != nil && task.RunAt.IsZero() {
+			task.RunAt = nil
+		}
+		task.UserID = workflow.UserID
+		task.Status = domain.TaskStatusPending
+		if len(parents[i]) > 0 {
+			task.Status = domain.TaskStatusBlocked
+		}
+		task.Attempts = 0
+		task.LastError = ""
+		task.Progress = 0
+		task.ProgressMessage = ""
+		task.CreatedAt = now
+		task.UpdatedAt = now
+		sorted[i] = task
+	}
+
+	if err := s.processor.Admit(ctx, sorted); err != nil {
+		return err
+	}
+	if err := s.repository.Create(ctx, workflow, sorted, parents); err != nil {
+		return err
+	}
+	// Once the tasks exist, queue them even if the caller goes away
+	ctx = context.WithoutCancel(ctx)
+
+	for _, task := range sorted {
+		s.events.Publish(task)
+		if task.Status != domain.TaskStatusPending {
+			continue
+		}
+		if s.processor.Process(ctx, task) != nil {
+			task.Status = domain.TaskStatusFailed
+			task.LastError = "could not queue task"
+			task.UpdatedAt = time.Now()
+			if s.tasks.Update(ctx, task, "last_error") == nil {
+				s.events.Publish(task)
+			}
+		}
+	}
+
+	workflow.Tasks = sorted
+	workflow.Summarize()
+	return nil
+}
+
+func (s *workflowService) Get(ctx context.Context, userID, id uint) (*domain.Workflow, error) {
+	workflow, err := s.repository.FindByIDForUser(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	workflow.Summarize()
+	return workflow, nil
+}
+
+// sortWorkflowTasks orders the tasks of a workflow so that every task comes
+// after its dependencies, using Kahn's algorithm. It returns the indexes of
+// the tasks in that order and, for each position, the positions of the
+// task's dependencies.
+func sortWorkflowTasks(tasks []domain.WorkflowTask) ([]int, [][]int, error) {
+	indexes := make(map[string]int, len(tasks))
+	for i, task := range tasks {
+		if task.Key == "" {
+			return nil, nil, fmt.Errorf("%w: task %d has no key", domain.ErrInvalidWorkflow, i)
+		}
+		if _, ok := indexes[task.Key]; ok {
+			return nil, nil, fmt.Errorf("%w: duplicate task key %q", domain.ErrInvalidWorkflow, task.Key)
+		}
+		indexes[task.Key] = i
+	}
+
+	dependencies := make([][]int, len(tasks))
+	dependents := make([][]int, len(tasks))
+	remaining := make([]int, len(tasks))
+	for i, task := range tasks {
+		seen := make(map[int]bool, len(task.DependsOn))
+		for _, key := range task.DependsOn {
+			parent, ok := indexes[key]
+			if !ok {
+				return nil, nil, fmt.Errorf("%w: task %q depends on unknown task %q", domain.ErrInvalidWorkflow, task.Key, key)
+			}
+			if seen[parent] {
+				continue
+			}
+			seen[parent] = true
+			dependencies[i] = append(dependencies[i], parent)
+			dependents[parent] = append(dependents[parent], i)
+			remaining[i]++
+		}
+	}
+
+	var ready, order []int
+	for i := range tasks {
+		if remaining[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	for len(ready) > 0 {
+		index := ready[0]
+		ready = ready[1:]
+		order = append(order, index)
+		for _, dependent := range dependents[index] {
+			remaining[dependent]--
+			if remaining[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(order) < len(tasks) {
+		for i := range tasks {
+			if remaining[i] > 0 {
+				return nil, nil, fmt.Errorf("%w: dependency cycle involving task %q", domain.ErrInvalidWorkflow, tasks[i].Key)
+			}
+		}
+	}
+
+	positions := make([]int, len(tasks))
+	for position, index := range order {
+		positions[index] = position
+	}
+	parents := make([][]int, len(order))
+	for position, index := range order {
+		for _, dependency := range dependencies[index] {
+			parents[position] = append(parents[position], positions[dependency])
+		}
+	}
+	return order, parents, nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golangwithgin/internal/domain"
+	"golangwithgin/internal/domain/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func workflowTask(key string, dependsOn ...string) domain.WorkflowTask {
+	return domain.WorkflowTask{Key: key, Task: &domain.Task{Title: key}, DependsOn: dependsOn}
+}
+
+func TestWorkflowService_SubmitRejectsInvalidGraphs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	registry := NewHandlerRegistry()
+	RegisterDefaultHandlers(registry)
+	service := NewWorkflowService(mocks.NewMockWorkflowRepository(ctrl), mocks.NewMockTaskRepository(ctrl), mocks.NewMockTaskProcessor(ctrl), registry, mocks.NewMockTaskEventPublisher(ctrl), RetryPolicy{})
+
+	invalid := map[string][]domain.WorkflowTask{
+		"empty":        nil,
+		"missing key":  {workflowTask("")},
+		"duplicate":    {workflowTask("a"), workflowTask("a")},
+		"unknown":      {workflowTask("a", "b")},
+		"self":         {workflowTask("a", "a")},
+		"cycle":        {workflowTask("a", "c"), workflowTask("b", "a"), workflowTask("c", "b"), workflowTask("d")},
+		"invalid task": {{Key: "a", Task: &domain.Task{Type: "missing"}}},
+	}
+	for name, tasks := range invalid {
+		err := service.Submit(context.Background(), &domain.Workflow{UserID: 1}, tasks)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestWorkflowService_SubmitOrdersTasks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repository := mocks.NewMockWorkflowRepository(ctrl)
+	processor := mocks.NewMockTaskProcessor(ctrl)
+	events := mocks.NewMockTaskEventPublisher(ctrl)
+	registry := NewHandlerRegistry()
+	RegisterDefaultHandlers(registry)
+	service := NewWorkflowService(repository, mocks.NewMockTaskRepository(ctrl), processor, registry, events, RetryPolicy{})
+
+	// report depends on both imports, which depend on download
+	tasks := []domain.WorkflowTask{
+		workflowTask("report", "import-a", "import-b"),
+		workflowTask("import-a", "download"),
+		workflowTask("import-b", "download", "download"),
+		workflowTask("download"),
+	}
+
+	// The blocked tasks are checked along with the rest, but take no room
+	// in the queue until they are unblocked
+	processor.EXPECT().Admit(gomock.Any(), gomock.Len(4)).Return(nil)
+	repository.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, workflow *domain.Workflow, sorted []*domain.Task, parents [][]int) error {
+			var titles []string
+			for _, task := range sorted {
+				titles = append(titles, task.Title)
+			}
+			assert.Equal(t, []string{"download", "import-a", "import-b", "report"}, titles)
+			assert.Equal(t, [][]int{nil, {0}, {0}, {1, 2}}, parents)
+			for i, task := range sorted {
+				task.ID = uint(i + 1)
+			}
+			return nil
+		})
+	events.EXPECT().Publish(gomock.Any()).Times(4)
+	processor.EXPECT().Process(gomock.Any(), tasks[3].Task).Return(nil)
+
+	workflow := &domain.Workflow{UserID: 1}
+	assert.NoError(t, service.Submit(context.Background(), workflow, tasks))
+
+	assert.Equal(t, domain.TaskStatusPending, tasks[3].Task.Status)
+	assert.Equal(t, domain.TaskStatusBlocked, tasks[0].Task.Status)
+	assert.Equal(t, domain.DependencyPolicyCancel, tasks[0].Task.DependencyPolicy)
+	assert.Equal(t, uint(1), tasks[0].Task.UserID)
+	assert.Equal(t, domain.WorkflowStatusPending, workflow.Status)
+	assert.Equal(t, 3, workflow.Counts[domain.TaskStatusBlocked])
+}
+
+func TestWorkflowService_SubmitFailsTasksThatCannotBeQueued(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repository := mocks.NewMockWorkflowRepository(ctrl)
+	taskRepository := mocks.NewMockTaskRepository(ctrl)
+	processor := mocks.NewMockTaskProcessor(ctrl)
+	events := mocks.NewMockTaskEventPublisher(ctrl)
+	registry := NewHandlerRegistry()
+	RegisterDefaultHandlers(registry)
+	service := NewWorkflowService(repository, taskRepository, processor, registry, events, RetryPolicy{})
+
+	tasks := []domain.WorkflowTask{workflowTask("a"), workflowTask("b")}
+
+	processor.EXPECT().Admit(gomock.Any(), gomock.Len(2)).Return(nil)
+	repository.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	events.EXPECT().Publish(gomock.Any()).Times(3)
+	// The first task cannot be queued, the second still is
+	processor.EXPECT().Process(gomock.Any(), tasks[0].Task).Return(errors.New("queue unavailable"))
+	taskRepository.EXPECT().Update(gomock.Any(), tasks[0].Task, "last_error").Return(nil)
+	processor.EXPECT().Process(gomock.Any(), tasks[1].Task).Return(nil)
+
+	workflow := &domain.Workflow{UserID: 1}
+	assert.NoError(t, service.Submit(context.Background(), workflow, tasks))
+
+	assert.Equal(t, domain.TaskStatusFailed, tasks[0].Task.Status)
+	assert.Equal(t, domain.TaskStatusPending, tasks[1].Task.Status)
+	assert.Equal(t, 1, workflow.Counts[domain.TaskStatusFailed])
+}
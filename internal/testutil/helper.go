@@ -11,7 +11,7 @@ func CreateTestTask() *domain.Task {
 		ID:          1,
 		Title:       "Test Task",
 		Description: "Test Description",
-		Status:      "pending",
+		Status:      domain.TaskStatusPending,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
@@ -25,7 +25,7 @@ func CreateTestTasks(count int) []*domain.Task {
 			ID:          uint(i + 1),
 			Title:       "Test Task " + string(rune(i+1)),
 			Description: "Test Description " + string(rune(i+1)),
-			Status:      "pending",
+			Status:      domain.TaskStatusPending,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		}
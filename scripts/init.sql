@@ -3,15 +3,174 @@ CREATE TABLE IF NOT EXISTS users (
     username VARCHAR(255) NOT NULL UNIQUE,
     password VARCHAR(255) NOT NULL,
     email VARCHAR(255) NOT NULL UNIQUE,
+    role VARCHAR(20) NOT NULL DEFAULT 'user',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
 );
 
+CREATE TABLE IF NOT EXISTS workflows (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_workflows_user_id (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
 CREATE TABLE IF NOT EXISTS tasks (
     id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
     title VARCHAR(255) NOT NULL,
     description TEXT,
+    type VARCHAR(100) NOT NULL DEFAULT 'default',
+    queue VARCHAR(100) NOT NULL DEFAULT 'default',
+    payload JSON,
+    priority INT NOT NULL DEFAULT 0,
+    run_at TIMESTAMP NULL,
     status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 0,
+    timeout INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    progress INT NOT NULL DEFAULT 0,
+    progress_message VARCHAR(255),
+    workflow_id BIGINT UNSIGNED,
+    dependency_policy VARCHAR(20),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_tasks_user_id (user_id),
+    INDEX idx_tasks_type (type),
+    INDEX idx_tasks_queue (queue),
+    INDEX idx_tasks_run_at (run_at),
+    INDEX idx_tasks_workflow_id (workflow_id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (workflow_id) REFERENCES workflows(id)
+);
+
+CREATE TABLE IF NOT EXISTS task_dependencies (
+    task_id BIGINT UNSIGNED NOT NULL,
+    depends_on_id BIGINT UNSIGNED NOT NULL,
+    PRIMARY KEY (task_id, depends_on_id),
+    INDEX idx_task_dependencies_depends_on_id (depends_on_id),
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+    FOREIGN KEY (depends_on_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS task_queue (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    task_id BIGINT UNSIGNED NOT NULL UNIQUE,
+    queue VARCHAR(100) NOT NULL DEFAULT 'default',
+    user_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    priority INT NOT NULL DEFAULT 0,
+    lease_owner VARCHAR(255),
+    lease_expires_at TIMESTAMP NULL,
+    available_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_task_queue_queue (queue),
+    INDEX idx_task_queue_user_id (user_id),
+    INDEX idx_task_queue_lease_expires_at (lease_expires_at),
+    INDEX idx_task_queue_available_at (available_at)
+);
+
+CREATE TABLE IF NOT EXISTS queue_states (
+    queue VARCHAR(100) PRIMARY KEY,
+    state VARCHAR(20) NOT NULL,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
-); 
\ No newline at end of file
+);
+
+CREATE TABLE IF NOT EXISTS task_results (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    task_id BIGINT UNSIGNED NOT NULL UNIQUE,
+    data JSON,
+    output_size BIGINT,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_task_results_expires_at (expires_at),
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS task_events (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    task_id BIGINT UNSIGNED NOT NULL,
+    user_id BIGINT UNSIGNED NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    status VARCHAR(50),
+    message TEXT,
+    progress INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_task_events_task_id (task_id),
+    INDEX idx_task_events_user_id (user_id),
+    INDEX idx_task_events_created_at (created_at)
+);
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    url VARCHAR(2048) NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    events JSON,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_webhooks_user_id (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    webhook_id BIGINT UNSIGNED NOT NULL,
+    task_id BIGINT UNSIGNED NOT NULL,
+    event VARCHAR(100) NOT NULL,
+    payload JSON,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    response_code INT,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_webhook_deliveries_webhook_id (webhook_id),
+    INDEX idx_webhook_deliveries_due (status, next_attempt_at)
+);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    response_code INT NOT NULL DEFAULT 0,
+    response_body JSON,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE INDEX idx_idempotency_keys_user_key (user_id, idempotency_key),
+    INDEX idx_idempotency_keys_expires_at (expires_at)
+);
+
+CREATE TABLE IF NOT EXISTS schedules (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(255),
+    cron_expression VARCHAR(100) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    task_title VARCHAR(255) NOT NULL,
+    task_description TEXT,
+    task_type VARCHAR(100) NOT NULL DEFAULT 'default',
+    task_queue VARCHAR(100),
+    task_payload JSON,
+    task_priority INT NOT NULL DEFAULT 0,
+    task_max_attempts INT NOT NULL DEFAULT 0,
+    task_timeout INT NOT NULL DEFAULT 0,
+    paused BOOLEAN NOT NULL DEFAULT FALSE,
+    next_run_at TIMESTAMP NULL,
+    last_run_at TIMESTAMP NULL,
+    last_task_id BIGINT UNSIGNED,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_schedules_user_id (user_id),
+    INDEX idx_schedules_next_run_at (next_run_at),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
\ No newline at end of file
@@ -5,16 +5,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"golangwithgin/config"
+	"golangwithgin/internal/app/handlers"
 	"golangwithgin/internal/app/server"
 	"golangwithgin/internal/domain"
+	"golangwithgin/internal/service"
 	"golangwithgin/pkg/logger"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/ory/dockertest/v3"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/driver/mysql"
@@ -91,6 +96,16 @@ func (s *TaskIntegrationTestSuite) SetupSuite() {
 			Secret:     "test-secret",
 			Expiration: 24 * time.Hour,
 		},
+		Tasks: config.TasksConfig{
+			Queues: map[string]config.TaskQueueConfig{
+				"reports": {MinWorkers: 1, Concurrency: 2, Capacity: 10, RateLimit: 5},
+				"fair":    {MinWorkers: 1, Concurrency: 1, Capacity: 10},
+			},
+		},
+		// Webhooks are delivered to a receiver on localhost
+		Webhooks: config.WebhooksConfig{
+			AllowPrivateTargets: true,
+		},
 		Logger: config.LoggerConfig{
 			Level: "info",
 			File:  "",  // Empty string for stdout only
@@ -109,18 +124,16 @@ func (s *TaskIntegrationTestSuite) SetupSuite() {
 }
 
 func (s *TaskIntegrationTestSuite) setupTestUser() {
-	// Create test user
-	user := &domain.User{
-		Username: "testuser",
-		Password: "testpass",
-		Email:    "test@example.com",
-	}
+	s.token = s.registerAndLogin("testuser", "testpass", "test@example.com")
+}
 
+// registerAndLogin registers a user and returns a JWT token for it
+func (s *TaskIntegrationTestSuite) registerAndLogin(username, password, email string) string {
 	// Register user
 	body, err := json.Marshal(map[string]string{
-		"username": user.Username,
-		"password": user.Password,
-		"email":    user.Email,
+		"username": username,
+		"password": password,
+		"email":    email,
 	})
 	s.Require().NoError(err)
 
@@ -138,7 +151,7 @@ func (s *TaskIntegrationTestSuite) setupTestUser() {
 	var response map[string]string
 	err = json.Unmarshal(w.Body.Bytes(), &response)
 	s.Require().NoError(err)
-	s.token = response["token"]
+	return response["token"]
 }
 
 func (s *TaskIntegrationTestSuite) TearDownSuite() {
@@ -168,7 +181,7 @@ func (s *TaskIntegrationTestSuite) TestTaskLifecycle() {
 	s.NotZero(createdTask.ID)
 	s.Equal(task["title"], createdTask.Title)
 	s.Equal(task["description"], createdTask.Description)
-	s.Equal("pending", createdTask.Status)
+	s.Equal(domain.TaskStatusPending, createdTask.Status)
 
 	// Wait for task processing
 	time.Sleep(2 * time.Second)
@@ -184,7 +197,7 @@ func (s *TaskIntegrationTestSuite) TestTaskLifecycle() {
 	err = json.Unmarshal(w.Body.Bytes(), &processedTask)
 	s.Require().NoError(err)
 	s.Equal(createdTask.ID, processedTask.ID)
-	s.Equal("completed", processedTask.Status)
+	s.Equal(domain.TaskStatusCompleted, processedTask.Status)
 
 	// Get all tasks
 	w = httptest.NewRecorder()
@@ -193,11 +206,16 @@ func (s *TaskIntegrationTestSuite) TestTaskLifecycle() {
 	s.server.GetRouter().ServeHTTP(w, req)
 	s.Equal(http.StatusOK, w.Code)
 
-	var tasks []domain.Task
-	err = json.Unmarshal(w.Body.Bytes(), &tasks)
+	var page struct {
+		Data       []domain.Task `json:"data"`
+		NextCursor string        `json:"next_cursor"`
+		Total      int64         `json:"total"`
+	}
+	err = json.Unmarshal(w.Body.Bytes(), &page)
 	s.Require().NoError(err)
-	s.NotEmpty(tasks)
-	s.Contains(tasks, processedTask)
+	s.NotEmpty(page.Data)
+	s.NotZero(page.Total)
+	s.Contains(page.Data, processedTask)
 }
 
 func (s *TaskIntegrationTestSuite) TestConcurrentTaskProcessing() {
@@ -237,6 +255,747 @@ func (s *TaskIntegrationTestSuite) TestConcurrentTaskProcessing() {
 		var processedTask domain.Task
 		err := json.Unmarshal(w.Body.Bytes(), &processedTask)
 		s.Require().NoError(err)
-		s.Equal("completed", processedTask.Status)
+		s.Equal(domain.TaskStatusCompleted, processedTask.Status)
+	}
+}
+
+func (s *TaskIntegrationTestSuite) TestTaskIsolation() {
+	otherToken := s.registerAndLogin("otheruser", "otherpass", "other@example.com")
+
+	// Create a task as the default test user
+	body, err := json.Marshal(map[string]string{
+		"title":       "Private Task",
+		"description": "Only visible to its owner",
+	})
+	s.Require().NoError(err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/tasks", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Equal(http.StatusAccepted, w.Code)
+
+	var createdTask domain.Task
+	err = json.Unmarshal(w.Body.Bytes(), &createdTask)
+	s.Require().NoError(err)
+	s.NotZero(createdTask.UserID)
+
+	// Another user cannot read it
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/api/v1/tasks/%d", createdTask.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+otherToken)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Equal(http.StatusNotFound, w.Code)
+
+	// Nor does it show up in their task list
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+otherToken)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Equal(http.StatusOK, w.Code)
+
+	var page struct {
+		Data []domain.Task `json:"data"`
+	}
+	err = json.Unmarshal(w.Body.Bytes(), &page)
+	s.Require().NoError(err)
+	for _, task := range page.Data {
+		s.NotEqual(createdTask.ID, task.ID)
+	}
+}
+
+func (s *TaskIntegrationTestSuite) TestTaskPagination() {
+	token := s.registerAndLogin("pageuser", "pagepass", "page@example.com")
+
+	for i := 0; i < 3; i++ {
+		body, err := json.Marshal(map[string]string{
+			"title":       fmt.Sprintf("Paged Task %d", i+1),
+			"description": "Testing pagination",
+		})
+		s.Require().NoError(err)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/tasks", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		s.server.GetRouter().ServeHTTP(w, req)
+		s.Equal(http.StatusAccepted, w.Code)
+	}
+
+	type taskPage struct {
+		Data       []domain.Task `json:"data"`
+		NextCursor string        `json:"next_cursor"`
+		Total      int64         `json:"total"`
+	}
+
+	// Walk the pages two tasks at a time, oldest first
+	seen := map[uint]bool{}
+	cursor := ""
+	for pages := 0; pages < 2; pages++ {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/tasks?limit=2&order=asc&title=Paged&cursor="+cursor, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		s.server.GetRouter().ServeHTTP(w, req)
+		s.Require().Equal(http.StatusOK, w.Code)
+
+		var page taskPage
+		s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &page))
+		s.Equal(int64(3), page.Total)
+		for _, task := range page.Data {
+			s.False(seen[task.ID])
+			seen[task.ID] = true
+		}
+		cursor = page.NextCursor
+	}
+	s.Len(seen, 3)
+	s.Empty(cursor)
+
+	// Invalid sort fields are rejected
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/tasks?sort_by=title", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *TaskIntegrationTestSuite) TestCreateTaskUnknownType() {
+	body, err := json.Marshal(map[string]interface{}{
+		"title":   "Unknown Type Task",
+		"type":    "does-not-exist",
+		"payload": map[string]string{"key": "value"},
+	})
+	s.Require().NoError(err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/tasks", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *TaskIntegrationTestSuite) TestCreateTaskTimeout() {
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/tasks", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+s.token)
+		s.server.GetRouter().ServeHTTP(w, req)
+		return w
+	}
+
+	s.Equal(http.StatusBadRequest, post(`{"title":"Negative Timeout","timeout":-1}`).Code)
+
+	w := post(`{"title":"Timeout Task","timeout":60}`)
+	s.Require().Equal(http.StatusAccepted, w.Code)
+	var task domain.Task
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &task))
+	s.Equal(60, task.Timeout)
+}
+
+func (s *TaskIntegrationTestSuite) TestTaskResult() {
+	body, err := json.Marshal(map[string]string{"title": "Result Task"})
+	s.Require().NoError(err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/tasks", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Require().Equal(http.StatusAccepted, w.Code)
+
+	var task domain.Task
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &task))
+
+	// The result is not available until the task completes
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/api/v1/tasks/%d/result", task.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Equal(http.StatusConflict, w.Code)
+
+	time.Sleep(2 * time.Second)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/api/v1/tasks/%d/result", task.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var result domain.TaskResult
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &result))
+	s.Equal(task.ID, result.TaskID)
+	s.NotEmpty(result.Data)
+}
+
+func (s *TaskIntegrationTestSuite) TestTaskEvents() {
+	body, err := json.Marshal(map[string]string{"title": "Streamed Task"})
+	s.Require().NoError(err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/tasks", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Require().Equal(http.StatusAccepted, w.Code)
+
+	var task domain.Task
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &task))
+
+	time.Sleep(2 * time.Second)
+
+	// The stream of a finished task replays its history and ends
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/api/v1/tasks/%d/events", task.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Equal("text/event-stream", w.Header().Get("Content-Type"))
+
+	stream := w.Body.String()
+	s.Contains(stream, `"status":"pending"`)
+	s.Contains(stream, `"status":"processing"`)
+	s.Contains(stream, `"status":"completed"`)
+	s.Contains(stream, "event: progress")
+	s.Contains(stream, `"progress":50`)
+
+	// The finished task keeps its last progress
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/api/v1/tasks/%d", task.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &task))
+	s.Equal(100, task.Progress)
+	s.Equal("Done", task.ProgressMessage)
+
+	// Resuming after the last event replays nothing
+	var lastID string
+	for _, line := range strings.Split(stream, "\n") {
+		if strings.HasPrefix(line, "id: ") {
+			lastID = strings.TrimPrefix(line, "id: ")
+		}
+	}
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/api/v1/tasks/%d/events", task.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	req.Header.Set("Last-Event-ID", lastID)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Equal(http.StatusOK, w.Code)
+	s.NotContains(w.Body.String(), "data:")
+}
+
+func (s *TaskIntegrationTestSuite) TestWebSocketSubmitAndFollow() {
+	httpServer := httptest.NewServer(s.server.GetRouter())
+	defer httpServer.Close()
+
+	url := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/api/v1/ws?access_token=" + s.token
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	read := func() handlers.WSMessage {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var msg handlers.WSMessage
+		s.Require().NoError(conn.ReadJSON(&msg))
+		return msg
+	}
+	s.Equal(handlers.WSMessageHello, read().Type)
+
+	s.Require().NoError(conn.WriteJSON(handlers.WSMessage{
+		Version: handlers.WebSocketProtocolVersion,
+		Type:    handlers.WSMessageSubmit,
+		ID:      "req-1",
+		Data:    json.RawMessage(`{"title":"WebSocket Task"}`),
+	}))
+
+	// The submission is acknowledged and its status changes follow
+	statuses := []domain.TaskStatus{}
+	for len(statuses) == 0 || statuses[len(statuses)-1] != domain.TaskStatusCompleted {
+		msg := read()
+		switch msg.Type {
+		case handlers.WSMessageSubmitted:
+			s.Equal("req-1", msg.ID)
+		case domain.TaskEventStatus:
+			var event domain.TaskEvent
+			s.Require().NoError(json.Unmarshal(msg.Data, &event))
+			statuses = append(statuses, event.Status)
+		default:
+			s.Failf("unexpected message", "%s: %s", msg.Type, msg.Data)
+			return
+		}
+	}
+	s.Contains(statuses, domain.TaskStatusProcessing)
+
+	// Unknown protocol versions are rejected
+	s.Require().NoError(conn.WriteJSON(handlers.WSMessage{Version: 2, Type: handlers.WSMessagePing, ID: "req-2"}))
+	msg := read()
+	s.Equal(handlers.WSMessageError, msg.Type)
+	s.Equal("req-2", msg.ID)
+}
+
+func (s *TaskIntegrationTestSuite) TestWebhookDelivery() {
+	token := s.registerAndLogin("hookuser", "hookpass", "hook@example.com")
+
+	type received struct {
+		body   []byte
+		header http.Header
+	}
+	deliveries := make(chan received, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- received{body: body, header: r.Header.Clone()}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	body, err := json.Marshal(map[string]interface{}{
+		"url":    receiver.URL,
+		"events": []string{"task.completed"},
+	})
+	s.Require().NoError(err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/webhooks", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Require().Equal(http.StatusCreated, w.Code)
+
+	var webhook struct {
+		ID     uint   `json:"id"`
+		Secret string `json:"secret"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &webhook))
+	s.Require().NotEmpty(webhook.Secret)
+
+	// The secret is not revealed again
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/api/v1/webhooks/%d", webhook.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.NotContains(w.Body.String(), webhook.Secret)
+
+	body, err = json.Marshal(map[string]string{"title": "Hooked Task"})
+	s.Require().NoError(err)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/tasks", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Require().Equal(http.StatusAccepted, w.Code)
+
+	// Only the subscribed event is delivered, signed with the secret
+	select {
+	case delivery := <-deliveries:
+		s.Equal("task.completed", delivery.header.Get(service.WebhookEventHeader))
+		s.Equal(service.SignWebhookPayload(webhook.Secret, delivery.body), delivery.header.Get(service.WebhookSignatureHeader))
+		s.Contains(string(delivery.body), `"title":"Hooked Task"`)
+	case <-time.After(10 * time.Second):
+		s.FailNow("webhook was not called")
+	}
+
+	// Give the dispatcher a moment to record the outcome
+	time.Sleep(500 * time.Millisecond)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/api/v1/webhooks/%d/deliveries", webhook.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var recorded []domain.WebhookDelivery
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &recorded))
+	s.Require().Len(recorded, 1)
+	s.Equal(domain.WebhookDeliverySucceeded, recorded[0].Status)
+	s.Equal(http.StatusOK, recorded[0].ResponseCode)
+
+	// Other users cannot see the webhook
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/api/v1/webhooks/%d", webhook.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *TaskIntegrationTestSuite) TestCreateTaskIdempotencyKey() {
+	token := s.registerAndLogin("idemuser", "idempass", "idem@example.com")
+
+	post := func(key, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/tasks", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set(handlers.IdempotencyKeyHeader, key)
+		s.server.GetRouter().ServeHTTP(w, req)
+		return w
+	}
+
+	first := post("submit-1", `{"title":"Idempotent Task"}`)
+	s.Require().Equal(http.StatusAccepted, first.Code)
+
+	// A retry replays the original response without creating another task
+	retry := post("submit-1", `{"title":"Idempotent Task"}`)
+	s.Require().Equal(http.StatusAccepted, retry.Code)
+	s.Equal("true", retry.Header().Get(handlers.IdempotentReplayedHeader))
+	s.JSONEq(first.Body.String(), retry.Body.String())
+
+	// Reusing the key for another request is rejected
+	s.Equal(http.StatusUnprocessableEntity, post("submit-1", `{"title":"Other Task"}`).Code)
+
+	// Keys are scoped to the user
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/tasks", strings.NewReader(`{"title":"Other Task"}`))
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	req.Header.Set(handlers.IdempotencyKeyHeader, "submit-1")
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Equal(http.StatusAccepted, w.Code)
+
+	var count int64
+	s.Require().NoError(s.db.Model(&domain.Task{}).Where("title = ?", "Idempotent Task").Count(&count).Error)
+	s.Equal(int64(1), count)
+}
+
+func (s *TaskIntegrationTestSuite) TestTaskPriorityAndQueuePosition() {
+	token := s.registerAndLogin("priorityuser", "prioritypass", "priority@example.com")
+
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/tasks", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		s.server.GetRouter().ServeHTTP(w, req)
+		return w
+	}
+
+	s.Equal(http.StatusBadRequest, post(`{"title":"Too Urgent","priority":10}`).Code)
+
+	// Submit more tasks than there are workers so some have to wait
+	for i := 0; i < 10; i++ {
+		s.Require().Equal(http.StatusAccepted, post(fmt.Sprintf(`{"title":"Bulk Task %d"}`, i)).Code)
+	}
+	w := post(`{"title":"Urgent Task","priority":9}`)
+	s.Require().Equal(http.StatusAccepted, w.Code)
+	var urgent domain.Task
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &urgent))
+	s.Equal(9, urgent.Priority)
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/tasks?status=pending&limit=100", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var page domain.TaskPage
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &page))
+	s.Require().NotEmpty(page.Tasks)
+
+	// The urgent task is ahead of every bulk task still waiting
+	positions := map[string]int{}
+	for _, task := range page.Tasks {
+		if task.QueuePosition != nil {
+			positions[task.Title] = *task.QueuePosition
+		}
+	}
+	s.Require().NotEmpty(positions)
+	if urgentPosition, ok := positions["Urgent Task"]; ok {
+		for title, position := range positions {
+			if title != "Urgent Task" {
+				s.Less(urgentPosition, position, title)
+			}
+		}
+	}
+}
+
+func (s *TaskIntegrationTestSuite) TestScheduledTask() {
+	post := func(title string, runAt time.Time) domain.Task {
+		body, err := json.Marshal(map[string]interface{}{"title": title, "run_at": runAt})
+		s.Require().NoError(err)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/tasks", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer "+s.token)
+		s.server.GetRouter().ServeHTTP(w, req)
+		s.Require().Equal(http.StatusAccepted, w.Code)
+
+		var task domain.Task
+		s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &task))
+		s.Require().NotNil(task.RunAt)
+		return task
+	}
+	get := func(id uint) domain.Task {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/tasks/%d", id), nil)
+		req.Header.Set("Authorization", "Bearer "+s.token)
+		s.server.GetRouter().ServeHTTP(w, req)
+		s.Require().Equal(http.StatusOK, w.Code)
+
+		var task domain.Task
+		s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &task))
+		return task
+	}
+
+	soon := post("Soon Task", time.Now().Add(3*time.Second))
+	later := post("Later Task", time.Now().Add(time.Hour))
+
+	// Nothing starts before its time
+	time.Sleep(time.Second)
+	s.Equal(domain.TaskStatusPending, get(soon.ID).Status)
+
+	time.Sleep(5 * time.Second)
+	s.Equal(domain.TaskStatusCompleted, get(soon.ID).Status)
+	s.Equal(domain.TaskStatusPending, get(later.ID).Status)
+
+	// A scheduled task can be cancelled before it starts
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/api/v1/tasks/%d/cancel", later.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	s.server.GetRouter().ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Equal(domain.TaskStatusCancelled, get(later.ID).Status)
+
+	var queued int64
+	s.Require().NoError(s.db.Model(&domain.QueuedTask{}).Where("task_id = ?", later.ID).Count(&queued).Error)
+	s.Zero(queued)
+}
+
+func (s *TaskIntegrationTestSuite) TestSchedules() {
+	token := s.registerAndLogin("scheduleuser", "schedulepass", "schedule@example.com")
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		s.server.GetRouter().ServeHTTP(w, req)
+		return w
+	}
+
+	s.Equal(http.StatusBadRequest, request("POST", "/api/v1/schedules", `{"cron_expression":"sometimes","task":{"title":"Report"}}`).Code)
+
+	w := request("POST", "/api/v1/schedules", `{"name":"Nightly","cron_expression":"0 2 * * *","timezone":"Europe/Berlin","task":{"title":"Scheduled Report"}}`)
+	s.Require().Equal(http.StatusCreated, w.Code)
+	var schedule domain.Schedule
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &schedule))
+	s.Require().NotNil(schedule.NextRunAt)
+	s.Nil(schedule.LastRunAt)
+
+	// Make the schedule due and let the scheduler pick it up
+	s.Require().NoError(s.db.Model(&domain.Schedule{}).Where("id = ?", schedule.ID).
+		Update("next_run_at", time.Now().Add(-time.Second)).Error)
+	time.Sleep(3 * time.Second)
+
+	w = request("GET", fmt.Sprintf("/api/v1/schedules/%d", schedule.ID), "")
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &schedule))
+	s.Require().NotNil(schedule.LastRunAt)
+	s.Require().NotNil(schedule.LastTaskID)
+	s.True(schedule.NextRunAt.After(time.Now()))
+
+	// Exactly one task was submitted for the run
+	var count int64
+	s.Require().NoError(s.db.Model(&domain.Task{}).Where("title = ?", "Scheduled Report").Count(&count).Error)
+	s.Equal(int64(1), count)
+
+	w = request("POST", fmt.Sprintf("/api/v1/schedules/%d/pause", schedule.ID), "")
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &schedule))
+	s.True(schedule.Paused)
+	s.Nil(schedule.NextRunAt)
+
+	w = request("POST", fmt.Sprintf("/api/v1/schedules/%d/resume", schedule.ID), "")
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &schedule))
+	s.False(schedule.Paused)
+	s.NotNil(schedule.NextRunAt)
+
+	s.Equal(http.StatusNoContent, request("DELETE", fmt.Sprintf("/api/v1/schedules/%d", schedule.ID), "").Code)
+	s.Equal(http.StatusNotFound, request("GET", fmt.Sprintf("/api/v1/schedules/%d", schedule.ID), "").Code)
+}
+
+func (s *TaskIntegrationTestSuite) TestWorkflows() {
+	token := s.registerAndLogin("workflowuser", "workflowpass", "workflow@example.com")
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		s.server.GetRouter().ServeHTTP(w, req)
+		return w
 	}
-} 
\ No newline at end of file
+
+	// Cycles and unknown keys are rejected before anything is stored
+	s.Equal(http.StatusBadRequest, request("POST", "/api/v1/workflows", `{"tasks":[{"key":"a","title":"A","depends_on":["b"]},{"key":"b","title":"B","depends_on":["a"]}]}`).Code)
+	s.Equal(http.StatusBadRequest, request("POST", "/api/v1/workflows", `{"tasks":[{"key":"a","title":"A","depends_on":["missing"]}]}`).Code)
+
+	w := request("POST", "/api/v1/workflows", `{"name":"Import","tasks":[
+		{"key":"report","title":"Report","depends_on":["import-a","import-b"]},
+		{"key":"import-a","title":"Import A","depends_on":["download"]},
+		{"key":"import-b","title":"Import B","depends_on":["download"]},
+		{"key":"download","title":"Download"}
+	]}`)
+	s.Require().Equal(http.StatusAccepted, w.Code)
+	var workflow domain.Workflow
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &workflow))
+	s.Require().Len(workflow.Tasks, 4)
+	s.Equal(domain.WorkflowStatusPending, workflow.Status)
+	s.Equal(3, workflow.Counts[domain.TaskStatusBlocked])
+
+	time.Sleep(8 * time.Second)
+
+	w = request("GET", fmt.Sprintf("/api/v1/workflows/%d", workflow.ID), "")
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &workflow))
+	s.Equal(domain.WorkflowStatusCompleted, workflow.Status)
+	s.Equal(4, workflow.Counts[domain.TaskStatusCompleted])
+	finished := make(map[string]time.Time)
+	for _, task := range workflow.Tasks {
+		finished[task.Title] = task.UpdatedAt
+	}
+	s.True(finished["Report"].After(finished["Import A"]))
+	s.True(finished["Import A"].After(finished["Download"]))
+
+	// Workflows are only visible to their owner
+	other := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/workflows/%d", workflow.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	s.server.GetRouter().ServeHTTP(other, req)
+	s.Equal(http.StatusNotFound, other.Code)
+
+	// Cancelling a task cascades to the tasks that depend on it
+	w = request("POST", "/api/v1/tasks", fmt.Sprintf(`{"title":"Parent","run_at":%q}`, time.Now().Add(time.Hour).Format(time.RFC3339)))
+	s.Require().Equal(http.StatusAccepted, w.Code)
+	var parent domain.Task
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &parent))
+
+	w = request("POST", "/api/v1/tasks", fmt.Sprintf(`{"title":"Child","depends_on":[%d]}`, parent.ID))
+	s.Require().Equal(http.StatusAccepted, w.Code)
+	var child domain.Task
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &child))
+	s.Equal(domain.TaskStatusBlocked, child.Status)
+
+	s.Require().Equal(http.StatusOK, request("POST", fmt.Sprintf("/api/v1/tasks/%d/cancel", parent.ID), "").Code)
+	w = request("GET", fmt.Sprintf("/api/v1/tasks/%d", child.ID), "")
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &child))
+	s.Equal(domain.TaskStatusCancelled, child.Status)
+	s.Equal(fmt.Sprintf("dependency %d cancelled", parent.ID), child.LastError)
+
+	// Unknown tasks cannot be depended on
+	s.Equal(http.StatusBadRequest, request("POST", "/api/v1/tasks", `{"title":"Sneaky","depends_on":[999999]}`).Code)
+}
+
+func (s *TaskIntegrationTestSuite) TestQueues() {
+	token := s.registerAndLogin("queueuser", "queuepass", "queue@example.com")
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		s.server.GetRouter().ServeHTTP(w, req)
+		return w
+	}
+
+	// Tasks go to the queue they name, which must be configured
+	s.Equal(http.StatusBadRequest, request("POST", "/api/v1/tasks", `{"title":"Lost","queue":"unknown"}`).Code)
+	w := request("POST", "/api/v1/tasks", `{"title":"Report","queue":"reports"}`)
+	s.Require().Equal(http.StatusAccepted, w.Code)
+	var task domain.Task
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &task))
+	s.Equal("reports", task.Queue)
+
+	// Queue stats are only shown to admins
+	s.Equal(http.StatusForbidden, request("GET", "/api/v1/queues", "").Code)
+	s.Require().NoError(s.db.Model(&domain.User{}).Where("username = ?", "queueuser").Update("role", domain.UserRoleAdmin).Error)
+
+	w = request("GET", "/api/v1/queues", "")
+	s.Require().Equal(http.StatusOK, w.Code)
+	var queues []domain.QueueStats
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &queues))
+	s.Require().Len(queues, 3)
+	s.Equal(domain.DefaultQueue, queues[0].Name)
+	s.Equal("fair", queues[1].Name)
+	s.Equal("reports", queues[2].Name)
+	s.Equal(2, queues[2].Concurrency)
+	s.Equal(10, queues[2].Capacity)
+	s.Equal(5.0, queues[2].RateLimit)
+	s.Equal(domain.QueueStateRunning, queues[2].State)
+}
+
+func (s *TaskIntegrationTestSuite) TestQueueControls() {
+	token := s.registerAndLogin("queueadmin", "queuepass", "queueadmin@example.com")
+	s.Require().NoError(s.db.Model(&domain.User{}).Where("username = ?", "queueadmin").Update("role", domain.UserRoleAdmin).Error)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		s.server.GetRouter().ServeHTTP(w, req)
+		return w
+	}
+	health := func() handlers.HealthResponse {
+		w := request("GET", "/api/v1/health", "")
+		s.Require().Equal(http.StatusOK, w.Code)
+		var response handlers.HealthResponse
+		s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+	s.Equal(domain.QueueStateRunning, health().Queues["reports"])
+	s.Equal(http.StatusNotFound, request("POST", "/api/v1/queues/missing/pause", "").Code)
+
+	// A paused queue accepts tasks and holds them
+	s.Require().Equal(http.StatusOK, request("POST", "/api/v1/queues/reports/pause", "").Code)
+	s.Equal(domain.QueueStatePaused, health().Queues["reports"])
+	// The state is stored for every server to follow
+	var stored domain.QueueStateRecord
+	s.Require().NoError(s.db.First(&stored, "queue = ?", "reports").Error)
+	s.Equal(domain.QueueStatePaused, stored.State)
+	w := request("POST", "/api/v1/tasks", `{"title":"Held","queue":"reports"}`)
+	s.Require().Equal(http.StatusAccepted, w.Code)
+	var task domain.Task
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &task))
+	time.Sleep(3 * time.Second)
+	w = request("GET", fmt.Sprintf("/api/v1/tasks/%d", task.ID), "")
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &task))
+	s.Equal(domain.TaskStatusPending, task.Status)
+
+	// A drained queue refuses them
+	s.Require().Equal(http.StatusOK, request("POST", "/api/v1/queues/reports/drain", "").Code)
+	s.Equal(domain.QueueStateDrained, health().Queues["reports"])
+	s.Equal(http.StatusServiceUnavailable, request("POST", "/api/v1/tasks", `{"title":"Refused","queue":"reports"}`).Code)
+
+	s.Require().Equal(http.StatusOK, request("POST", "/api/v1/queues/reports/resume", "").Code)
+	s.Equal(domain.QueueStateRunning, health().Queues["reports"])
+	time.Sleep(8 * time.Second)
+	w = request("GET", fmt.Sprintf("/api/v1/tasks/%d", task.ID), "")
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &task))
+	s.Equal(domain.TaskStatusCompleted, task.Status)
+}
+
+func (s *TaskIntegrationTestSuite) TestFairScheduling() {
+	busyToken := s.registerAndLogin("busyuser", "busypass", "busy@example.com")
+	quietToken := s.registerAndLogin("quietuser", "quietpass", "quiet@example.com")
+
+	submit := func(token, title string) domain.Task {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/tasks", strings.NewReader(fmt.Sprintf(`{"title":%q,"queue":"fair"}`, title)))
+		req.Header.Set("Authorization", "Bearer "+token)
+		s.server.GetRouter().ServeHTTP(w, req)
+		s.Require().Equal(http.StatusAccepted, w.Code)
+		var task domain.Task
+		s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &task))
+		return task
+	}
+	status := func(token string, id uint) domain.TaskStatus {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/tasks/%d", id), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		s.server.GetRouter().ServeHTTP(w, req)
+		s.Require().Equal(http.StatusOK, w.Code)
+		var task domain.Task
+		s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &task))
+		return task.Status
+	}
+
+	// The queue runs one task at a time; the quiet user's task goes second
+	// rather than after the busy user's backlog
+	var busy []domain.Task
+	for i := 0; i < 4; i++ {
+		busy = append(busy, submit(busyToken, fmt.Sprintf("Busy %d", i)))
+	}
+	quiet := submit(quietToken, "Quiet")
+
+	time.Sleep(2500 * time.Millisecond)
+	s.Equal(domain.TaskStatusCompleted, status(quietToken, quiet.ID))
+	s.Equal(domain.TaskStatusPending, status(busyToken, busy[3].ID))
+}